@@ -123,6 +123,11 @@ func parseTerragruntOptionsFromArgs(terragruntVersion string, args []string, wri
 
 	debug := parseBooleanArg(args, OPT_TERRAGRUNT_DEBUG, false)
 
+	parentFoldersStopFile, err := parseStringArg(args, OPT_TERRAGRUNT_PARENT_FOLDERS_STOP_FILE, os.Getenv("TERRAGRUNT_PARENT_FOLDERS_STOP_FILE"))
+	if err != nil {
+		return nil, err
+	}
+
 	opts, err := options.NewTerragruntOptions(filepath.ToSlash(terragruntConfigPath))
 	if err != nil {
 		return nil, err
@@ -170,6 +175,7 @@ func parseTerragruntOptionsFromArgs(terragruntVersion string, args []string, wri
 	opts.HclFile = filepath.ToSlash(terragruntHclFilePath)
 	opts.Debug = debug
 	opts.AwsProviderPatchOverrides = awsProviderPatchOverrides
+	opts.FindInParentFoldersStopFile = parentFoldersStopFile
 
 	return opts, nil
 }
@@ -130,6 +130,12 @@ func TestParseTerragruntOptionsFromArgs(t *testing.T) {
 			nil,
 		},
 
+		{
+			[]string{"--terragrunt-parent-folders-stop-file", ".terragrunt-root"},
+			mockOptionsWithParentFoldersStopFile(t, util.JoinPath(workingDir, config.DefaultTerragruntConfigPath), workingDir, []string{}, ".terragrunt-root"),
+			nil,
+		},
+
 		{
 			[]string{"--terragrunt-config"},
 			nil,
@@ -176,6 +182,7 @@ func assertOptionsEqual(t *testing.T, expected options.TerragruntOptions, actual
 	assert.Equal(t, expected.IamRole, actual.IamRole, msgAndArgs...)
 	assert.Equal(t, expected.Debug, actual.Debug, msgAndArgs...)
 	assert.Equal(t, expected.AwsProviderPatchOverrides, actual.AwsProviderPatchOverrides, msgAndArgs...)
+	assert.Equal(t, expected.FindInParentFoldersStopFile, actual.FindInParentFoldersStopFile, msgAndArgs...)
 }
 
 func mockOptions(t *testing.T, terragruntConfigPath string, workingDir string, terraformCliArgs []string, nonInteractive bool, terragruntSource string, ignoreDependencyErrors bool, includeExternalDependencies bool, debugMode bool) *options.TerragruntOptions {
@@ -202,6 +209,12 @@ func mockOptionsWithIamRole(t *testing.T, terragruntConfigPath string, workingDi
 	return opts
 }
 
+func mockOptionsWithParentFoldersStopFile(t *testing.T, terragruntConfigPath string, workingDir string, terraformCliArgs []string, stopFile string) *options.TerragruntOptions {
+	opts := mockOptions(t, terragruntConfigPath, workingDir, terraformCliArgs, false, "", false, false, false)
+	opts.FindInParentFoldersStopFile = stopFile
+	return opts
+}
+
 func mockOptionsWithOverrideAttrs(t *testing.T, terragruntConfigPath string, workingDir string, terraformCliArgs []string, overrideAttrs map[string]string) *options.TerragruntOptions {
 	opts := mockOptions(t, terragruntConfigPath, workingDir, terraformCliArgs, false, "", false, false, false)
 	opts.AwsProviderPatchOverrides = overrideAttrs
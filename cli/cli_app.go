@@ -43,6 +43,7 @@ const OPT_TERRAGRUNT_CHECK = "terragrunt-check"
 const OPT_TERRAGRUNT_HCLFMT_FILE = "terragrunt-hclfmt-file"
 const OPT_TERRAGRUNT_DEBUG = "terragrunt-debug"
 const OPT_TERRAGRUNT_OVERRIDE_ATTR = "terragrunt-override-attr"
+const OPT_TERRAGRUNT_PARENT_FOLDERS_STOP_FILE = "terragrunt-parent-folders-stop-file"
 
 var ALL_TERRAGRUNT_BOOLEAN_OPTS = []string{
 	OPT_NON_INTERACTIVE,
@@ -69,6 +70,7 @@ var ALL_TERRAGRUNT_STRING_OPTS = []string{
 	OPT_TERRAGRUNT_PARALLELISM,
 	OPT_TERRAGRUNT_HCLFMT_FILE,
 	OPT_TERRAGRUNT_OVERRIDE_ATTR,
+	OPT_TERRAGRUNT_PARENT_FOLDERS_STOP_FILE,
 }
 
 const CMD_PLAN_ALL = "plan-all"
@@ -83,6 +85,7 @@ const CMD_TERRAGRUNT_INFO = "terragrunt-info"
 const CMD_TERRAGRUNT_GRAPH_DEPENDENCIES = "graph-dependencies"
 const CMD_TERRAGRUNT_READ_CONFIG = "terragrunt-read-config"
 const CMD_HCLFMT = "hclfmt"
+const CMD_RENDER_LOCALS = "render-locals"
 const CMD_AWS_PROVIDER_PATCH = "aws-provider-patch"
 
 // CMD_SPIN_UP is deprecated.
@@ -122,6 +125,7 @@ var TERRAFORM_COMMANDS_THAT_DO_NOT_NEED_INIT = []string{
 	"version",
 	"terragrunt-info",
 	"graph-dependencies",
+	"render-locals",
 }
 
 // Struct is output as JSON by 'terragrunt-info':
@@ -156,6 +160,7 @@ COMMANDS:
    terragrunt-info      Emits limited terragrunt state on stdout and exits
    graph-dependencies   Prints the terragrunt dependency graph to stdout
    hclfmt               Recursively find terragrunt.hcl files and rewrite them into a canonical format.
+   render-locals        Prints the evaluated locals of the terragrunt config as JSON to stdout
    aws-provider-patch   Overwrite settings on nested AWS providers to work around a Terraform bug (issue #13018)
    *                    Terragrunt forwards all other commands directly to Terraform
 
@@ -181,6 +186,7 @@ GLOBAL OPTIONS:
    terragrunt-hclfmt-file                       The path to a single terragrunt.hcl file that the hclfmt command should run on.
    terragrunt-override-attr                     A key=value attribute to override in a provider block as part of the aws-provider-patch command. May be specified multiple times.
    terragrunt-debug                             Write terragrunt-debug.tfvars to working folder to help root-cause issues.
+   terragrunt-parent-folders-stop-file          Stop find_in_parent_folders from searching above a folder containing this file. Can also be set via the TERRAGRUNT_PARENT_FOLDERS_STOP_FILE environment variable.
 
 VERSION:
    {{.Version}}{{if len .Authors}}
@@ -284,6 +290,10 @@ func RunTerragrunt(terragruntOptions *options.TerragruntOptions) error {
 		return runGraphDependencies(terragruntOptions)
 	}
 
+	if shouldRenderLocals(terragruntOptions) {
+		return renderLocals(terragruntOptions)
+	}
+
 	if err := checkVersionConstraints(terragruntOptions); err != nil {
 		return err
 	}
@@ -440,6 +450,22 @@ func runGraphDependencies(terragruntOptions *options.TerragruntOptions) error {
 	return nil
 }
 
+// renderLocals prints the evaluated locals of the terragrunt config as JSON to stdout
+func renderLocals(terragruntOptions *options.TerragruntOptions) error {
+	locals, err := config.ReadTerragruntConfigLocals(terragruntOptions)
+	if err != nil {
+		return err
+	}
+
+	localsJson, err := config.MarshalLocals(locals)
+	if err != nil {
+		terragruntOptions.Logger.Printf("JSON error marshalling locals")
+		return err
+	}
+	fmt.Fprintf(terragruntOptions.Writer, "%s\n", localsJson)
+	return nil
+}
+
 func shouldPrintTerraformHelp(terragruntOptions *options.TerragruntOptions) bool {
 	for _, tfHelpFlag := range TERRAFORM_HELP_FLAGS {
 		if util.ListContainsElement(terragruntOptions.TerraformCliArgs, tfHelpFlag) {
@@ -453,6 +479,10 @@ func shouldRunGraphDependencies(terragruntOptions *options.TerragruntOptions) bo
 	return util.ListContainsElement(terragruntOptions.TerraformCliArgs, CMD_TERRAGRUNT_GRAPH_DEPENDENCIES)
 }
 
+func shouldRenderLocals(terragruntOptions *options.TerragruntOptions) bool {
+	return util.ListContainsElement(terragruntOptions.TerraformCliArgs, CMD_RENDER_LOCALS)
+}
+
 func shouldPrintTerragruntInfo(terragruntOptions *options.TerragruntOptions) bool {
 	return util.ListContainsElement(terragruntOptions.TerraformCliArgs, CMD_TERRAGRUNT_INFO)
 }
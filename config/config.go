@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
@@ -22,6 +23,9 @@ import (
 const DefaultTerragruntConfigPath = "terragrunt.hcl"
 const DefaultTerragruntJsonConfigPath = "terragrunt.hcl.json"
 
+// The byte order mark some editors (notably on Windows) prepend to UTF-8 encoded files
+const utf8ByteOrderMark = "\uFEFF"
+
 // TerragruntConfig represents a parsed and expanded configuration
 // NOTE: if any attributes are added, make sure to update terragruntConfigAsCty in config_as_cty.go
 type TerragruntConfig struct {
@@ -140,6 +144,32 @@ type terragruntGenerateBlock struct {
 	DisableSignature *bool   `hcl:"disable_signature,attr"`
 }
 
+// Convert the parsed generate block into the GenerateConfig struct used by codegen, filling in the defaults for optional
+// attributes.
+func (block terragruntGenerateBlock) toConfig() (codegen.GenerateConfig, error) {
+	ifExists, err := codegen.GenerateConfigExistsFromString(block.IfExists)
+	if err != nil {
+		return codegen.GenerateConfig{}, err
+	}
+	genConfig := codegen.GenerateConfig{
+		Path:        block.Path,
+		IfExists:    ifExists,
+		IfExistsStr: block.IfExists,
+		Contents:    block.Contents,
+	}
+	if block.CommentPrefix == nil {
+		genConfig.CommentPrefix = codegen.DefaultCommentPrefix
+	} else {
+		genConfig.CommentPrefix = *block.CommentPrefix
+	}
+	if block.DisableSignature == nil {
+		genConfig.DisableSignature = false
+	} else {
+		genConfig.DisableSignature = *block.DisableSignature
+	}
+	return genConfig, nil
+}
+
 // IncludeConfig represents the configuration settings for a parent Terragrunt configuration file that you can
 // "include" in a child Terragrunt configuration file
 type IncludeConfig struct {
@@ -341,8 +371,13 @@ func ReadTerragruntConfig(terragruntOptions *options.TerragruntOptions) (*Terrag
 
 // Parse the Terragrunt config file at the given path. If the include parameter is not nil, then treat this as a config
 // included in some other config file when resolving relative paths.
+//
+// The given filename is the file that is read, and is used to choose the parser and in error messages. To parse a copy
+// of a config (e.g. in a temp dir) as if it lived at its logical path, set terragruntOptions.TerragruntConfigPath, which
+// include resolution and helper functions such as get_terragrunt_dir use, and terragruntOptions.LogicalPath, which
+// relative paths in functions such as file and templatefile are resolved against, to the logical path.
 func ParseConfigFile(filename string, terragruntOptions *options.TerragruntOptions, include *IncludeConfig) (*TerragruntConfig, error) {
-	configString, err := util.ReadFileAsString(filename)
+	configString, err := readConfigFileAsString(filename)
 	if err != nil {
 		return nil, err
 	}
@@ -355,6 +390,23 @@ func ParseConfigFile(filename string, terragruntOptions *options.TerragruntOptio
 	return config, nil
 }
 
+// readConfigFileAsString returns the contents of the Terragrunt config file at the given path as a string. A leading
+// UTF-8 byte order mark, which some editors add and which the HCL parser does not understand, is stripped. Files that
+// are not UTF-8 encoded (e.g. UTF-16) can't be parsed, so we return a clear error instead of a cryptic parse error.
+func readConfigFileAsString(filename string) (string, error) {
+	configString, err := util.ReadFileAsString(filename)
+	if err != nil {
+		return "", err
+	}
+
+	// UTF-16 without a byte order mark is mostly ASCII with NUL bytes in between, which is valid UTF-8, so also reject
+	// NUL bytes, which never appear in a UTF-8 encoded config.
+	if !utf8.ValidString(configString) || strings.ContainsRune(configString, '\x00') {
+		return "", errors.WithStackTrace(UnsupportedEncodingError{Filename: filename})
+	}
+	return strings.TrimPrefix(configString, utf8ByteOrderMark), nil
+}
+
 // Parse the Terragrunt config contained in the given string and merge it with the given include config (if any). Note
 // that the config parsing consists of multiple stages so as to allow referencing of data resulting from parsing
 // previous config. The parsing order is:
@@ -428,6 +480,13 @@ func ParseConfigString(configString string, terragruntOptions *options.Terragrun
 		if err != nil {
 			return nil, err
 		}
+		if terragruntOptions.WarnOnEmptyInclude && !contributesToMerge(includedConfig) {
+			terragruntOptions.Logger.Printf(
+				"WARNING: The config %s included by %s does not set anything that is merged into the including config. Check that the include path is correct.",
+				terragruntInclude.Include.Path,
+				filename,
+			)
+		}
 		return mergeConfigWithIncludedConfig(config, includedConfig, terragruntOptions)
 	} else {
 		return config, nil
@@ -520,6 +579,23 @@ func parseHcl(parser *hclparse.Parser, hcl string, filename string) (file *hcl.F
 	return file, nil
 }
 
+// contributesToMerge returns true if the given included config sets anything that is merged into the including config.
+// Note that locals are scoped to the defining config, so they are not considered.
+func contributesToMerge(includedConfig *TerragruntConfig) bool {
+	return includedConfig.Terraform != nil ||
+		includedConfig.TerraformBinary != "" ||
+		includedConfig.TerraformVersionConstraint != "" ||
+		includedConfig.TerragruntVersionConstraint != "" ||
+		includedConfig.RemoteState != nil ||
+		includedConfig.Dependencies != nil ||
+		includedConfig.DownloadDir != "" ||
+		includedConfig.PreventDestroy != nil ||
+		includedConfig.IamRole != "" ||
+		len(includedConfig.Inputs) > 0 ||
+		len(includedConfig.TerragruntDependencies) > 0 ||
+		len(includedConfig.GenerateConfigs) > 0
+}
+
 // Merge the given config with an included config. Anything specified in the current config will override the contents
 // of the included config. If the included config is nil, just return the current config.
 func mergeConfigWithIncludedConfig(config *TerragruntConfig, includedConfig *TerragruntConfig, terragruntOptions *options.TerragruntOptions) (*TerragruntConfig, error) {
@@ -573,7 +649,11 @@ func mergeConfigWithIncludedConfig(config *TerragruntConfig, includedConfig *Ter
 	}
 
 	// Merge the generate configs. This is a shallow merge. Meaning, if the child has the same name generate block, then the
-	// child's generate block will override the parent's block.
+	// child's generate block will override the parent's block. The included config may not have any generate blocks
+	// of its own, in which case its map is nil and writing the child's blocks into it would panic, so create it first.
+	if len(config.GenerateConfigs) > 0 && includedConfig.GenerateConfigs == nil {
+		includedConfig.GenerateConfigs = map[string]codegen.GenerateConfig{}
+	}
 	for key, val := range config.GenerateConfigs {
 		includedConfig.GenerateConfigs[key] = val
 	}
@@ -663,8 +743,30 @@ func getIndexOfExtraArgsWithName(extraArgs []TerraformExtraArguments, name strin
 
 // Parse the config of the given include, if one is specified
 func parseIncludedConfig(includedConfig *IncludeConfig, terragruntOptions *options.TerragruntOptions) (*TerragruntConfig, error) {
-	if includedConfig.Path == "" {
-		return nil, errors.WithStackTrace(IncludedConfigMissingPath(terragruntOptions.TerragruntConfigPath))
+	includePath, err := getIncludedConfigPath(includedConfig, terragruntOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseConfigFile(includePath, includedConfigOptions(terragruntOptions), includedConfig)
+}
+
+// includedConfigOptions returns the options to parse an included config with. The LogicalPath of the including config
+// does not apply to the included config, which is parsed at its own path.
+func includedConfigOptions(terragruntOptions *options.TerragruntOptions) *options.TerragruntOptions {
+	if terragruntOptions.LogicalPath == "" {
+		return terragruntOptions
+	}
+	includedOptions := *terragruntOptions
+	includedOptions.LogicalPath = ""
+	return &includedOptions
+}
+
+// getIncludedConfigPath returns the path to the config file of the given include. Relative paths are resolved against
+// the directory of the including config.
+func getIncludedConfigPath(includedConfig *IncludeConfig, terragruntOptions *options.TerragruntOptions) (string, error) {
+	if includedConfig == nil || includedConfig.Path == "" {
+		return "", errors.WithStackTrace(IncludedConfigMissingPath(terragruntOptions.TerragruntConfigPath))
 	}
 
 	includePath := includedConfig.Path
@@ -673,7 +775,11 @@ func parseIncludedConfig(includedConfig *IncludeConfig, terragruntOptions *optio
 		includePath = util.JoinPath(filepath.Dir(terragruntOptions.TerragruntConfigPath), includePath)
 	}
 
-	return ParseConfigFile(includePath, terragruntOptions, includedConfig)
+	if util.IsDir(includePath) {
+		return "", errors.WithStackTrace(IncludePathIsDirectoryError{Path: includePath})
+	}
+
+	return includePath, nil
 }
 
 func mergeInputs(childInputs map[string]interface{}, parentInputs map[string]interface{}) map[string]interface{} {
@@ -756,26 +862,10 @@ func convertToTerragruntConfig(
 	}
 
 	for _, block := range terragruntConfigFromFile.GenerateBlocks {
-		ifExists, err := codegen.GenerateConfigExistsFromString(block.IfExists)
+		genConfig, err := block.toConfig()
 		if err != nil {
 			return nil, err
 		}
-		genConfig := codegen.GenerateConfig{
-			Path:        block.Path,
-			IfExists:    ifExists,
-			IfExistsStr: block.IfExists,
-			Contents:    block.Contents,
-		}
-		if block.CommentPrefix == nil {
-			genConfig.CommentPrefix = codegen.DefaultCommentPrefix
-		} else {
-			genConfig.CommentPrefix = *block.CommentPrefix
-		}
-		if block.DisableSignature == nil {
-			genConfig.DisableSignature = false
-		} else {
-			genConfig.DisableSignature = *block.DisableSignature
-		}
 		terragruntConfig.GenerateConfigs[block.Name] = genConfig
 	}
 
@@ -813,6 +903,14 @@ func (err IncludedConfigMissingPath) Error() string {
 	return fmt.Sprintf("The include configuration in %s must specify a 'path' parameter", string(err))
 }
 
+type IncludePathIsDirectoryError struct {
+	Path string
+}
+
+func (err IncludePathIsDirectoryError) Error() string {
+	return fmt.Sprintf("The include path %s is a directory. Please point it at the Terragrunt config file within it (e.g. %s).", err.Path, DefaultConfigPath(err.Path))
+}
+
 type TooManyLevelsOfInheritance struct {
 	ConfigPath             string
 	FirstLevelIncludePath  string
@@ -823,6 +921,14 @@ func (err TooManyLevelsOfInheritance) Error() string {
 	return fmt.Sprintf("%s includes %s, which itself includes %s. Only one level of includes is allowed.", err.ConfigPath, err.FirstLevelIncludePath, err.SecondLevelIncludePath)
 }
 
+type UnsupportedEncodingError struct {
+	Filename string
+}
+
+func (err UnsupportedEncodingError) Error() string {
+	return fmt.Sprintf("The Terragrunt config %s is not UTF-8 encoded. Please convert it to UTF-8.", err.Filename)
+}
+
 type CouldNotResolveTerragruntConfigInFile string
 
 func (err CouldNotResolveTerragruntConfigInFile) Error() string {
@@ -6,11 +6,13 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"sort"
 	"strings"
 	"unicode/utf8"
 
 	"github.com/hashicorp/go-getter"
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	tflang "github.com/hashicorp/terraform/lang"
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/function"
@@ -55,6 +57,13 @@ var TERRAFORM_COMMANDS_NEED_INPUT = []string{
 	"refresh",
 }
 
+// Functions that have been renamed, mapped to the function that replaces them. These are used to point users at the
+// replacement instead of failing with a generic "unknown function" error.
+var RENAMED_FUNCTIONS = map[string]string{
+	"get_tfvars_dir":        "get_terragrunt_dir",
+	"get_parent_tfvars_dir": "get_parent_terragrunt_dir",
+}
+
 // List of terraform commands that accept -parallelism=
 var TERRAFORM_COMMANDS_NEED_PARALLELISM = []string{
 	"apply",
@@ -85,14 +94,45 @@ type EvalContextExtensions struct {
 }
 
 // Create an EvalContext for the HCL2 parser. We can define functions and variables in this context that the HCL2 parser
-// will make available to the Terragrunt configuration during parsing.
+// will make available to the Terragrunt configuration during parsing. Functions in ExtraFunctions are added on top of
+// the built-in functions; use validateExtraFunctions to reject unintended overrides before calling this.
 func CreateTerragruntEvalContext(
 	filename string,
 	terragruntOptions *options.TerragruntOptions,
 	extensions EvalContextExtensions,
 ) *hcl.EvalContext {
+	functions := createBuiltinFunctions(filename, terragruntOptions, extensions)
+	for k, v := range terragruntOptions.ExtraFunctions {
+		functions[k] = v
+	}
+
+	ctx := &hcl.EvalContext{
+		Functions: functions,
+	}
+	ctx.Variables = map[string]cty.Value{}
+	if extensions.Locals != nil {
+		ctx.Variables["local"] = *extensions.Locals
+	}
+	if extensions.DecodedDependencies != nil {
+		ctx.Variables["dependency"] = *extensions.DecodedDependencies
+	}
+	return ctx
+}
+
+// createBuiltinFunctions returns the Terraform and Terragrunt functions available to every Terragrunt config.
+func createBuiltinFunctions(
+	filename string,
+	terragruntOptions *options.TerragruntOptions,
+	extensions EvalContextExtensions,
+) map[string]function.Function {
+	// Relative paths in functions such as file are resolved against the directory of the config, or of its logical path
+	// if it was read from somewhere else.
+	baseDir := filepath.Dir(filename)
+	if terragruntOptions.LogicalPath != "" {
+		baseDir = filepath.Dir(terragruntOptions.LogicalPath)
+	}
 	tfscope := tflang.Scope{
-		BaseDir: filepath.Dir(filename),
+		BaseDir: baseDir,
 	}
 
 	terragruntFunctions := map[string]function.Function{
@@ -124,18 +164,55 @@ func CreateTerragruntEvalContext(
 	for k, v := range terragruntFunctions {
 		functions[k] = v
 	}
+	return functions
+}
 
-	ctx := &hcl.EvalContext{
-		Functions: functions,
+// validateExtraFunctions returns an error if a function in ExtraFunctions has the same name as a built-in function,
+// unless AllowExtraFunctionOverrides is set.
+func validateExtraFunctions(terragruntOptions *options.TerragruntOptions) error {
+	if len(terragruntOptions.ExtraFunctions) == 0 || terragruntOptions.AllowExtraFunctionOverrides {
+		return nil
 	}
-	ctx.Variables = map[string]cty.Value{}
-	if extensions.Locals != nil {
-		ctx.Variables["local"] = *extensions.Locals
+
+	builtins := createBuiltinFunctions(terragruntOptions.TerragruntConfigPath, terragruntOptions, EvalContextExtensions{})
+	names := []string{}
+	for name := range terragruntOptions.ExtraFunctions {
+		names = append(names, name)
 	}
-	if extensions.DecodedDependencies != nil {
-		ctx.Variables["dependency"] = *extensions.DecodedDependencies
+	sort.Strings(names)
+	for _, name := range names {
+		if _, isBuiltin := builtins[name]; isBuiltin {
+			return errors.WithStackTrace(ExtraFunctionOverridesBuiltin{Name: name})
+		}
 	}
-	return ctx
+	return nil
+}
+
+// renamedFunctionDiagnostics returns an error diagnostic for each call to a renamed function in the given expression,
+// suggesting the function that replaces it.
+func renamedFunctionDiagnostics(expr hcl.Expression) hcl.Diagnostics {
+	// Expressions from JSON configs are not walkable, so we can only detect these in HCL syntax.
+	syntaxExpr, isSyntaxExpr := expr.(hclsyntax.Expression)
+	if !isSyntaxExpr {
+		return nil
+	}
+
+	return hclsyntax.VisitAll(syntaxExpr, func(node hclsyntax.Node) hcl.Diagnostics {
+		call, isCall := node.(*hclsyntax.FunctionCallExpr)
+		if !isCall {
+			return nil
+		}
+		replacement, isRenamed := RENAMED_FUNCTIONS[call.Name]
+		if !isRenamed {
+			return nil
+		}
+		return hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Call to renamed function",
+			Detail:   fmt.Sprintf("The function %s has been renamed to %s. Please call %s instead.", call.Name, replacement, replacement),
+			Subject:  &call.NameRange,
+		}}
+	})
 }
 
 // Return the OS platform
@@ -269,6 +346,14 @@ func findInParentFolders(params []string, include *IncludeConfig, terragruntOpti
 	// To avoid getting into an accidental infinite loop (e.g. do to cyclical symlinks), set a max on the number of
 	// parent folders we'll check
 	for i := 0; i < terragruntOptions.MaxFoldersToCheck; i++ {
+		// Never search above a folder that contains the stop file, if one is configured
+		if stopFile := terragruntOptions.FindInParentFoldersStopFile; stopFile != "" && util.FileExists(util.JoinPath(previousDir, stopFile)) {
+			if numParams == 2 {
+				return fallbackParam, nil
+			}
+			return "", errors.WithStackTrace(ParentFileNotFound{Path: terragruntOptions.TerragruntConfigPath, File: fileToFindStr, Cause: fmt.Sprintf("Reached the stop file %s in %s", stopFile, previousDir)})
+		}
+
 		currentDir := filepath.ToSlash(filepath.Dir(previousDir))
 		if currentDir == previousDir {
 			if numParams == 2 {
@@ -376,8 +461,24 @@ func readTerragruntConfig(configPath string, defaultVal *cty.Value, terragruntOp
 		return *defaultVal, nil
 	}
 
+	// Track the chain of configs being read so that configs that read each other are reported as an error, instead of
+	// recursing forever.
+	currentConfig, err := util.CanonicalPath(terragruntOptions.TerragruntConfigPath, "")
+	if err != nil {
+		return cty.NilVal, errors.WithStackTrace(err)
+	}
+	canonicalTargetConfig, err := util.CanonicalPath(targetConfig, "")
+	if err != nil {
+		return cty.NilVal, errors.WithStackTrace(err)
+	}
+	readChain := append(util.CloneStringList(terragruntOptions.ReadTerragruntConfigChain), currentConfig)
+	if util.ListContainsElement(readChain, canonicalTargetConfig) {
+		return cty.NilVal, errors.WithStackTrace(ReadTerragruntConfigCycle{Chain: append(readChain, canonicalTargetConfig)})
+	}
+
 	// We update the context of terragruntOptions to the config being read in.
 	targetOptions := terragruntOptions.Clone(targetConfig)
+	targetOptions.ReadTerragruntConfigChain = readChain
 	config, err := ParseConfigFile(targetConfig, targetOptions, nil)
 	if err != nil {
 		return cty.NilVal, err
@@ -605,6 +706,22 @@ func (err TerragruntConfigNotFound) Error() string {
 	return fmt.Sprintf("Terragrunt config %s not found", err.Path)
 }
 
+type ReadTerragruntConfigCycle struct {
+	Chain []string
+}
+
+func (err ReadTerragruntConfigCycle) Error() string {
+	return fmt.Sprintf("Found a cycle in read_terragrunt_config calls: %s", strings.Join(err.Chain, " -> "))
+}
+
+type ExtraFunctionOverridesBuiltin struct {
+	Name string
+}
+
+func (err ExtraFunctionOverridesBuiltin) Error() string {
+	return fmt.Sprintf("The extra function %s has the same name as a built-in function. Set AllowExtraFunctionOverrides to replace the built-in function.", err.Name)
+}
+
 type InvalidSourceUrl struct {
 	ModulePath       string
 	ModuleSourceUrl  string
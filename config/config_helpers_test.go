@@ -9,6 +9,7 @@ import (
 	"github.com/gruntwork-io/terragrunt/errors"
 	"github.com/gruntwork-io/terragrunt/options"
 	"github.com/gruntwork-io/terragrunt/test/helpers"
+	"github.com/gruntwork-io/terragrunt/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/zclconf/go-cty/cty"
@@ -254,6 +255,62 @@ func TestFindInParentFolders(t *testing.T) {
 	}
 }
 
+func TestFindInParentFoldersStopFile(t *testing.T) {
+	t.Parallel()
+
+	childConfigPath := "../test/fixture-parent-folders/stop-file/infra/app/child/" + DefaultTerragruntConfigPath
+
+	testCases := []struct {
+		params       []string
+		stopFile     string
+		expectedPath string
+		expectedErr  error
+	}{
+		{
+			nil,
+			"",
+			absPath(t, "../test/fixture-parent-folders/stop-file/"+DefaultTerragruntConfigPath),
+			nil,
+		},
+		{
+			nil,
+			".terragrunt-root",
+			"",
+			ParentFileNotFound{},
+		},
+		{
+			[]string{DefaultTerragruntConfigPath, "fallback.hcl"},
+			".terragrunt-root",
+			"fallback.hcl",
+			nil,
+		},
+		{
+			[]string{"common.hcl"},
+			".terragrunt-root",
+			absPath(t, "../test/fixture-parent-folders/stop-file/infra/common.hcl"),
+			nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(fmt.Sprintf("%v-%s", testCase.params, testCase.stopFile), func(t *testing.T) {
+			terragruntOptions := terragruntOptionsForTest(t, childConfigPath)
+			terragruntOptions.FindInParentFoldersStopFile = testCase.stopFile
+
+			actualPath, actualErr := findInParentFolders(testCase.params, nil, terragruntOptions)
+			if testCase.expectedErr != nil {
+				if assert.Error(t, actualErr) {
+					assert.IsType(t, testCase.expectedErr, errors.Unwrap(actualErr))
+				}
+			} else {
+				assert.Nil(t, actualErr)
+				assert.Equal(t, testCase.expectedPath, actualPath)
+			}
+		})
+	}
+}
+
 func TestResolveTerragruntInterpolation(t *testing.T) {
 	t.Parallel()
 
@@ -865,6 +922,21 @@ func TestReadTerragruntConfigLocals(t *testing.T) {
 	assert.Equal(t, localsMap["number_expression"].(float64), float64(42))
 }
 
+func TestReadTerragruntConfigCycle(t *testing.T) {
+	t.Parallel()
+
+	options := terragruntOptionsForTest(t, DefaultTerragruntConfigPath)
+	configA, err := util.CanonicalPath("../test/fixture-read-config/cycle/a/terragrunt.hcl", "")
+	require.NoError(t, err)
+	configB, err := util.CanonicalPath("../test/fixture-read-config/cycle/b/terragrunt.hcl", "")
+	require.NoError(t, err)
+
+	_, err = readTerragruntConfig(configA, nil, options)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Found a cycle in read_terragrunt_config calls")
+	assert.Contains(t, err.Error(), fmt.Sprintf("%s -> %s -> %s", configA, configB, configA))
+}
+
 func TestGetTerragruntSourceForModuleHappyPath(t *testing.T) {
 	t.Parallel()
 
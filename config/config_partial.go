@@ -3,11 +3,13 @@ package config
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/zclconf/go-cty/cty"
 
+	"github.com/gruntwork-io/terragrunt/codegen"
 	"github.com/gruntwork-io/terragrunt/errors"
 	"github.com/gruntwork-io/terragrunt/options"
 	"github.com/gruntwork-io/terragrunt/util"
@@ -25,6 +27,7 @@ const (
 	TerragruntFlags
 	TerragruntVersionConstraints
 	RemoteStateBlock
+	GenerateBlock
 )
 
 // terragruntInclude is a struct that can be used to only decode the include block.
@@ -87,6 +90,12 @@ type terragruntRemoteState struct {
 	Remain      hcl.Body               `hcl:",remain"`
 }
 
+// terragruntGenerate is a struct that can be used to only decode the generate blocks in the terragrunt config
+type terragruntGenerate struct {
+	GenerateBlocks []terragruntGenerateBlock `hcl:"generate,block"`
+	Remain         hcl.Body                  `hcl:",remain"`
+}
+
 // DecodeBaseBlocks takes in a parsed HCL2 file and decodes the base blocks. Base blocks are blocks that should always
 // be decoded even in partial decoding, because they provide bindings that are necessary for parsing any block in the
 // file. Currently base blocks are:
@@ -99,6 +108,10 @@ func DecodeBaseBlocks(
 	filename string,
 	includeFromChild *IncludeConfig,
 ) (*cty.Value, *terragruntInclude, *IncludeConfig, error) {
+	if err := validateExtraFunctions(terragruntOptions); err != nil {
+		return nil, nil, nil, err
+	}
+
 	// Decode just the `include` block, and verify that it's allowed here
 	terragruntInclude, err := decodeAsTerragruntInclude(
 		hclFile,
@@ -134,7 +147,7 @@ func PartialParseConfigFile(
 	include *IncludeConfig,
 	decodeList []PartialDecodeSectionType,
 ) (*TerragruntConfig, error) {
-	configString, err := util.ReadFileAsString(filename)
+	configString, err := readConfigFileAsString(filename)
 	if err != nil {
 		return nil, err
 	}
@@ -157,6 +170,7 @@ func PartialParseConfigFile(
 // - TerragruntVersionConstraints: Parses the attributes related to constraining terragrunt and terraform versions in
 //                                 the config.
 // - RemoteStateBlock: Parses the `remote_state` block in the config
+// - GenerateBlock: Parses the `generate` blocks in the config
 // Note that the following blocks are always decoded:
 // - locals
 // - include
@@ -169,17 +183,31 @@ func PartialParseConfigString(
 	filename string,
 	decodeList []PartialDecodeSectionType,
 ) (*TerragruntConfig, error) {
+	config, _, err := PartialParseConfigStringWithIncludes(configString, terragruntOptions, includeFromChild, filename, decodeList)
+	return config, err
+}
+
+// PartialParseConfigStringWithIncludes is the same as PartialParseConfigString, but also returns the canonical paths of
+// the configs that were included during the partial parse. This is useful for tools that need to know which files the
+// result depends on, e.g. to compute cache keys, without doing a full parse.
+func PartialParseConfigStringWithIncludes(
+	configString string,
+	terragruntOptions *options.TerragruntOptions,
+	includeFromChild *IncludeConfig,
+	filename string,
+	decodeList []PartialDecodeSectionType,
+) (*TerragruntConfig, []string, error) {
 	// Parse the HCL string into an AST body that can be decoded multiple times later without having to re-parse
 	parser := hclparse.NewParser()
 	file, err := parseHcl(parser, configString, filename)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Decode just the Base blocks. See the function docs for DecodeBaseBlocks for more info on what base blocks are.
 	localsAsCty, terragruntInclude, includeForDecode, err := DecodeBaseBlocks(terragruntOptions, parser, file, filename, includeFromChild)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Initialize evaluation context extensions from base blocks.
@@ -198,7 +226,7 @@ func PartialParseConfigString(
 			decoded := terragruntDependencies{}
 			err := decodeHcl(file, filename, &decoded, terragruntOptions, contextExtensions)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 
 			// If we already decoded some dependencies, merge them in. Otherwise, set as the new list.
@@ -212,7 +240,7 @@ func PartialParseConfigString(
 			decoded := terragruntTerraform{}
 			err := decodeHcl(file, filename, &decoded, terragruntOptions, contextExtensions)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			output.Terraform = decoded.Terraform
 
@@ -220,7 +248,7 @@ func PartialParseConfigString(
 			decoded := terragruntTerraformSource{}
 			err := decodeHcl(file, filename, &decoded, terragruntOptions, contextExtensions)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			if decoded.Terraform != nil {
 				output.Terraform = &TerraformConfig{Source: decoded.Terraform.Source}
@@ -230,7 +258,7 @@ func PartialParseConfigString(
 			decoded := terragruntDependency{}
 			err := decodeHcl(file, filename, &decoded, terragruntOptions, contextExtensions)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			output.TerragruntDependencies = decoded.Dependencies
 
@@ -247,7 +275,7 @@ func PartialParseConfigString(
 			decoded := terragruntFlags{}
 			err := decodeHcl(file, filename, &decoded, terragruntOptions, contextExtensions)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			if decoded.PreventDestroy != nil {
 				output.PreventDestroy = decoded.PreventDestroy
@@ -263,7 +291,7 @@ func PartialParseConfigString(
 			decoded := terragruntVersionConstraints{}
 			err := decodeHcl(file, filename, &decoded, terragruntOptions, contextExtensions)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			if decoded.TerragruntVersionConstraint != nil {
 				output.TerragruntVersionConstraint = *decoded.TerragruntVersionConstraint
@@ -279,49 +307,205 @@ func PartialParseConfigString(
 			decoded := terragruntRemoteState{}
 			err := decodeHcl(file, filename, &decoded, terragruntOptions, contextExtensions)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			if decoded.RemoteState != nil {
 				remoteState, err := decoded.RemoteState.toConfig()
 				if err != nil {
-					return nil, err
+					return nil, nil, err
 				}
 				output.RemoteState = remoteState
 			}
 
+		case GenerateBlock:
+			decoded := terragruntGenerate{}
+			err := decodeHcl(file, filename, &decoded, terragruntOptions, contextExtensions)
+			if err != nil {
+				return nil, nil, err
+			}
+			if output.GenerateConfigs == nil {
+				output.GenerateConfigs = map[string]codegen.GenerateConfig{}
+			}
+			for _, block := range decoded.GenerateBlocks {
+				genConfig, err := block.toConfig()
+				if err != nil {
+					return nil, nil, err
+				}
+				output.GenerateConfigs[block.Name] = genConfig
+			}
+
 		default:
-			return nil, InvalidPartialBlockName{decode}
+			return nil, nil, InvalidPartialBlockName{decode}
 		}
 	}
 
 	// If this file includes another, parse and merge the partial blocks.  Otherwise just return this config.
 	if terragruntInclude.Include != nil {
-		includedConfig, err := partialParseIncludedConfig(terragruntInclude.Include, terragruntOptions, decodeList)
+		includedConfig, includePath, err := partialParseIncludedConfig(terragruntInclude.Include, terragruntOptions, decodeList)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		return mergeConfigWithIncludedConfig(&output, includedConfig, terragruntOptions)
+		config, err := mergeConfigWithIncludedConfig(&output, includedConfig, terragruntOptions)
+		if err != nil {
+			return nil, nil, err
+		}
+		return config, []string{includePath}, nil
 	}
-	return &output, nil
+	return &output, []string{}, nil
 }
 
-func partialParseIncludedConfig(includedConfig *IncludeConfig, terragruntOptions *options.TerragruntOptions, decodeList []PartialDecodeSectionType) (*TerragruntConfig, error) {
-	if includedConfig.Path == "" {
-		return nil, errors.WithStackTrace(IncludedConfigMissingPath(terragruntOptions.TerragruntConfigPath))
+// partialParseIncludedConfig partially parses the config of the given include, returning it along with its canonical
+// path.
+func partialParseIncludedConfig(includedConfig *IncludeConfig, terragruntOptions *options.TerragruntOptions, decodeList []PartialDecodeSectionType) (*TerragruntConfig, string, error) {
+	includePath, err := getIncludedConfigPath(includedConfig, terragruntOptions)
+	if err != nil {
+		return nil, "", err
 	}
-
-	includePath := includedConfig.Path
-
-	if !filepath.IsAbs(includePath) {
-		includePath = util.JoinPath(filepath.Dir(terragruntOptions.TerragruntConfigPath), includePath)
+	includePath, err = util.CanonicalPath(includePath, "")
+	if err != nil {
+		return nil, "", err
 	}
 
-	return PartialParseConfigFile(
+	config, err := PartialParseConfigFile(
 		includePath,
-		terragruntOptions,
+		includedConfigOptions(terragruntOptions),
 		includedConfig,
 		decodeList,
 	)
+	if err != nil {
+		return nil, "", err
+	}
+	return config, includePath, nil
+}
+
+// ListBlocks returns the types of the top level blocks that are present in the given parsed config, in sorted order and
+// without duplicates. This only inspects the block headers, so nothing in the config is evaluated.
+func ListBlocks(hclFile *hcl.File) ([]string, error) {
+	blocksSchema := &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "include"},
+			{Type: "locals"},
+			{Type: "terraform"},
+			{Type: "dependency", LabelNames: []string{"name"}},
+			{Type: "dependencies"},
+			{Type: "generate", LabelNames: []string{"name"}},
+			{Type: "remote_state"},
+		},
+	}
+	// We use PartialContent here, because we are only interested in the blocks and not in the attributes.
+	content, _, diags := hclFile.Body.PartialContent(blocksSchema)
+	if diags.HasErrors() {
+		return nil, errors.WithStackTrace(diags)
+	}
+
+	blockTypes := []string{}
+	for _, block := range content.Blocks {
+		if !util.ListContainsElement(blockTypes, block.Type) {
+			blockTypes = append(blockTypes, block.Type)
+		}
+	}
+	sort.Strings(blockTypes)
+	return blockTypes, nil
+}
+
+// ResolveIncludeChain returns the canonical paths of the given config and of the config it includes (if any), starting
+// with the given config. Only the `include` blocks are decoded, so this is much cheaper than a partial parse, but it also
+// means that locals are not available: an include path that references locals results in an error.
+func ResolveIncludeChain(filename string, terragruntOptions *options.TerragruntOptions) ([]string, error) {
+	currentPath, err := util.CanonicalPath(filename, "")
+	if err != nil {
+		return nil, err
+	}
+
+	chain := []string{}
+	for {
+		chain = append(chain, currentPath)
+
+		include, err := decodeIncludeBlockOnly(currentPath, terragruntOptions.Clone(currentPath))
+		if err != nil {
+			return nil, err
+		}
+		if include == nil {
+			return chain, nil
+		}
+		if len(chain) > 1 {
+			return nil, errors.WithStackTrace(TooManyLevelsOfInheritance{
+				ConfigPath:             chain[0],
+				FirstLevelIncludePath:  chain[1],
+				SecondLevelIncludePath: include.Path,
+			})
+		}
+		if include.Path == "" {
+			return nil, errors.WithStackTrace(IncludedConfigMissingPath(currentPath))
+		}
+
+		currentPath, err = util.CanonicalPath(include.Path, filepath.Dir(currentPath))
+		if err != nil {
+			return nil, err
+		}
+		if util.IsDir(currentPath) {
+			return nil, errors.WithStackTrace(IncludePathIsDirectoryError{Path: currentPath})
+		}
+	}
+}
+
+// ResolveIncludeChainFromRoot is like ResolveIncludeChain, but orders the paths from root to leaf, so the given config
+// comes last instead of first.
+func ResolveIncludeChainFromRoot(filename string, terragruntOptions *options.TerragruntOptions) ([]string, error) {
+	chain, err := ResolveIncludeChain(filename, terragruntOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	fromRoot := make([]string, len(chain))
+	for i, path := range chain {
+		fromRoot[len(chain)-1-i] = path
+	}
+	return fromRoot, nil
+}
+
+// decodeIncludeBlockOnly reads the given config and decodes only its `include` block, without evaluating locals.
+func decodeIncludeBlockOnly(filename string, terragruntOptions *options.TerragruntOptions) (*IncludeConfig, error) {
+	configString, err := readConfigFileAsString(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := hclparse.NewParser()
+	file, err := parseHcl(parser, configString, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without this check, referencing a local would surface as a generic unknown variable error from the decoder.
+	includeSchema := &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "include"},
+		},
+	}
+	parsedInclude, _, diags := file.Body.PartialContent(includeSchema)
+	if diags.HasErrors() {
+		return nil, errors.WithStackTrace(diags)
+	}
+	for _, block := range parsedInclude.Blocks {
+		attrs, diags := block.Body.JustAttributes()
+		if diags.HasErrors() {
+			return nil, errors.WithStackTrace(diags)
+		}
+		for _, attr := range attrs {
+			for _, traversal := range attr.Expr.Variables() {
+				if traversal.RootName() == "local" {
+					return nil, errors.WithStackTrace(IncludeReferencesLocals{ConfigPath: filename})
+				}
+			}
+		}
+	}
+
+	terragruntInclude, err := decodeAsTerragruntInclude(file, filename, terragruntOptions, EvalContextExtensions{})
+	if err != nil {
+		return nil, err
+	}
+	return terragruntInclude.Include, nil
 }
 
 // This decodes only the `include` block of a terragrunt config, so its value can be used while decoding the rest of the
@@ -352,3 +536,11 @@ type InvalidPartialBlockName struct {
 func (err InvalidPartialBlockName) Error() string {
 	return fmt.Sprintf("Unrecognized partial block code %d. This is most likely an error in terragrunt. Please file a bug report on the project repository.", err.sectionCode)
 }
+
+type IncludeReferencesLocals struct {
+	ConfigPath string
+}
+
+func (err IncludeReferencesLocals) Error() string {
+	return fmt.Sprintf("The include block in %s references locals, which are not evaluated when resolving the include chain.", err.ConfigPath)
+}
@@ -3,6 +3,10 @@ package config
 import (
 	"testing"
 
+	"github.com/gruntwork-io/terragrunt/codegen"
+	"github.com/gruntwork-io/terragrunt/errors"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -315,3 +319,221 @@ terraform {
 	require.NotNil(t, terragruntConfig.Terraform.Source)
 	assert.Equal(t, *terragruntConfig.Terraform.Source, "../../modules/app")
 }
+
+func TestPartialParseTerraformBlockResolvesLocals(t *testing.T) {
+	t.Parallel()
+
+	config := `
+locals {
+  registry = "git::git@github.com:acme/modules.git"
+  version  = "v0.0.1"
+}
+
+terraform {
+  source = "${local.registry}//app?ref=${local.version}"
+}
+
+inputs = {
+  # This function call will fail when attempting to decode
+  file = file("i-am-a-file-that-does-not-exist")
+}
+`
+
+	for _, section := range []PartialDecodeSectionType{TerraformBlock, TerraformSource} {
+		terragruntConfig, err := PartialParseConfigString(config, mockOptionsForTest(t), nil, DefaultTerragruntConfigPath, []PartialDecodeSectionType{section})
+		require.NoError(t, err)
+		assert.True(t, terragruntConfig.IsPartial)
+
+		require.NotNil(t, terragruntConfig.Terraform)
+		require.NotNil(t, terragruntConfig.Terraform.Source)
+		assert.Equal(t, "git::git@github.com:acme/modules.git//app?ref=v0.0.1", *terragruntConfig.Terraform.Source)
+		assert.Nil(t, terragruntConfig.Inputs)
+	}
+}
+
+func TestPartialParseGenerateBlock(t *testing.T) {
+	t.Parallel()
+
+	config := `
+locals {
+  region = "us-east-1"
+}
+
+generate "provider" {
+  path      = "provider.tf"
+  if_exists = "overwrite"
+  contents  = "provider \"aws\" { region = \"${local.region}\" }"
+}
+
+generate "backend" {
+  path              = "backend.tf"
+  if_exists         = "skip"
+  comment_prefix    = "/* "
+  contents          = "terraform {}"
+  disable_signature = true
+}
+
+inputs = {
+  # This function call will fail when attempting to decode
+  file = file("i-am-a-file-that-does-not-exist")
+}
+`
+
+	terragruntConfig, err := PartialParseConfigString(config, mockOptionsForTest(t), nil, DefaultTerragruntConfigPath, []PartialDecodeSectionType{GenerateBlock})
+	require.NoError(t, err)
+	assert.True(t, terragruntConfig.IsPartial)
+	assert.Nil(t, terragruntConfig.Inputs)
+
+	require.Len(t, terragruntConfig.GenerateConfigs, 2)
+
+	provider := terragruntConfig.GenerateConfigs["provider"]
+	assert.Equal(t, "provider.tf", provider.Path)
+	assert.Equal(t, codegen.ExistsOverwrite, provider.IfExists)
+	assert.Equal(t, codegen.DefaultCommentPrefix, provider.CommentPrefix)
+	assert.Equal(t, `provider "aws" { region = "us-east-1" }`, provider.Contents)
+	assert.False(t, provider.DisableSignature)
+
+	backend := terragruntConfig.GenerateConfigs["backend"]
+	assert.Equal(t, "backend.tf", backend.Path)
+	assert.Equal(t, codegen.ExistsSkip, backend.IfExists)
+	assert.Equal(t, "/* ", backend.CommentPrefix)
+	assert.True(t, backend.DisableSignature)
+}
+
+func TestPartialParseConfigStringWithIncludes(t *testing.T) {
+	t.Parallel()
+
+	configPath := "../test/fixture-partial-parse/partial-inheritance/child/" + DefaultTerragruntConfigPath
+	opts := mockOptionsForTestWithConfigPath(t, configPath)
+	configString, err := readConfigFileAsString(configPath)
+	require.NoError(t, err)
+
+	terragruntConfig, includedPaths, err := PartialParseConfigStringWithIncludes(configString, opts, nil, configPath, []PartialDecodeSectionType{TerragruntFlags})
+	require.NoError(t, err)
+	assert.True(t, terragruntConfig.IsPartial)
+	assert.Equal(t, []string{absPath(t, "../test/fixture-partial-parse/partial-inheritance/"+DefaultTerragruntConfigPath)}, includedPaths)
+}
+
+func TestPartialParseConfigStringWithIncludesNoInclude(t *testing.T) {
+	t.Parallel()
+
+	_, includedPaths, err := PartialParseConfigStringWithIncludes("skip = true", mockOptionsForTest(t), nil, DefaultTerragruntConfigPath, []PartialDecodeSectionType{TerragruntFlags})
+	require.NoError(t, err)
+	assert.Empty(t, includedPaths)
+}
+
+func TestResolveIncludeChain(t *testing.T) {
+	t.Parallel()
+
+	opts := mockOptionsForTestWithConfigPath(t, "../test/fixture-partial-parse/partial-inheritance/child/"+DefaultTerragruntConfigPath)
+	chain, err := ResolveIncludeChain(opts.TerragruntConfigPath, opts)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[]string{
+			absPath(t, "../test/fixture-partial-parse/partial-inheritance/child/"+DefaultTerragruntConfigPath),
+			absPath(t, "../test/fixture-partial-parse/partial-inheritance/"+DefaultTerragruntConfigPath),
+		},
+		chain,
+	)
+}
+
+func TestResolveIncludeChainFromRoot(t *testing.T) {
+	t.Parallel()
+
+	opts := mockOptionsForTestWithConfigPath(t, "../test/fixture-partial-parse/partial-inheritance/child/"+DefaultTerragruntConfigPath)
+	chain, err := ResolveIncludeChainFromRoot(opts.TerragruntConfigPath, opts)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[]string{
+			absPath(t, "../test/fixture-partial-parse/partial-inheritance/"+DefaultTerragruntConfigPath),
+			absPath(t, "../test/fixture-partial-parse/partial-inheritance/child/"+DefaultTerragruntConfigPath),
+		},
+		chain,
+	)
+}
+
+func TestResolveIncludeChainNoInclude(t *testing.T) {
+	t.Parallel()
+
+	opts := mockOptionsForTestWithConfigPath(t, "../test/fixture-partial-parse/partial-inheritance/"+DefaultTerragruntConfigPath)
+	chain, err := ResolveIncludeChain(opts.TerragruntConfigPath, opts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{absPath(t, opts.TerragruntConfigPath)}, chain)
+}
+
+func TestResolveIncludeChainErrorsOnLocalsReference(t *testing.T) {
+	t.Parallel()
+
+	opts := mockOptionsForTestWithConfigPath(t, "../test/fixture-partial-parse/include-references-locals/child/"+DefaultTerragruntConfigPath)
+	_, err := ResolveIncludeChain(opts.TerragruntConfigPath, opts)
+	require.Error(t, err)
+	assert.IsType(t, IncludeReferencesLocals{}, errors.Unwrap(err))
+}
+
+func TestResolveIncludeChainErrorsOnMalformedInclude(t *testing.T) {
+	t.Parallel()
+
+	opts := mockOptionsForTestWithConfigPath(t, "../test/fixture-partial-parse/malformed-include/child/"+DefaultTerragruntConfigPath)
+	_, err := ResolveIncludeChain(opts.TerragruntConfigPath, opts)
+	require.Error(t, err)
+	assert.IsType(t, hcl.Diagnostics{}, errors.Unwrap(err))
+}
+
+func TestListBlocks(t *testing.T) {
+	t.Parallel()
+
+	config := `
+include {
+  path = find_in_parent_folders()
+}
+
+locals {
+  region = "us-east-1"
+}
+
+dependency "vpc" {
+  config_path = "../vpc"
+}
+
+dependency "mysql" {
+  config_path = "../mysql"
+}
+
+generate "provider" {
+  path     = "provider.tf"
+  contents = file("i-am-a-file-that-does-not-exist")
+}
+
+inputs = {
+  region = local.region
+}
+`
+
+	file, err := parseHcl(hclparse.NewParser(), config, DefaultTerragruntConfigPath)
+	require.NoError(t, err)
+
+	blocks, err := ListBlocks(file)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dependency", "generate", "include", "locals"}, blocks)
+}
+
+func TestIncludePathIsDirectory(t *testing.T) {
+	t.Parallel()
+
+	configPath := "../test/fixture-partial-parse/include-directory/child/terragrunt.hcl"
+	opts := mockOptionsForTestWithConfigPath(t, configPath)
+
+	_, err := ParseConfigFile(configPath, opts, nil)
+	require.Error(t, err)
+	assert.IsType(t, IncludePathIsDirectoryError{}, errors.Unwrap(err))
+
+	_, err = PartialParseConfigFile(configPath, opts, nil, []PartialDecodeSectionType{TerragruntFlags})
+	require.Error(t, err)
+	assert.IsType(t, IncludePathIsDirectoryError{}, errors.Unwrap(err))
+
+	_, err = ResolveIncludeChain(configPath, opts)
+	require.Error(t, err)
+	assert.IsType(t, IncludePathIsDirectoryError{}, errors.Unwrap(err))
+}
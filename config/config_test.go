@@ -1,11 +1,14 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/gruntwork-io/terragrunt/codegen"
 	"github.com/gruntwork-io/terragrunt/errors"
 	"github.com/gruntwork-io/terragrunt/options"
 	"github.com/gruntwork-io/terragrunt/remote"
@@ -378,6 +381,291 @@ include {
 
 }
 
+func TestParseTerragruntConfigMalformedInclude(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		config string
+	}{
+		{"MissingPath", "include {}"},
+		{"EmptyPath", "include {\n  path = \"\"\n}"},
+		{"Labeled", "include \"parent\" {\n  path = \"../terragrunt.hcl\"\n}"},
+	}
+
+	for _, testCase := range testCases {
+		// Capture range variable so that it is brought into the scope within the for loop, so that it is stable even
+		// when subtests are run in parallel.
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := mockOptionsForTest(t)
+			_, err := ParseConfigString(testCase.config, opts, nil, DefaultTerragruntConfigPath)
+			assert.Error(t, err)
+
+			_, err = PartialParseConfigString(testCase.config, opts, nil, DefaultTerragruntConfigPath, []PartialDecodeSectionType{DependenciesBlock})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseIncludedConfigNilInclude(t *testing.T) {
+	t.Parallel()
+
+	opts := mockOptionsForTest(t)
+
+	_, err := parseIncludedConfig(nil, opts)
+	assert.IsType(t, IncludedConfigMissingPath(""), errors.Unwrap(err))
+
+	_, _, err = partialParseIncludedConfig(nil, opts, []PartialDecodeSectionType{DependenciesBlock})
+	assert.IsType(t, IncludedConfigMissingPath(""), errors.Unwrap(err))
+}
+
+func TestParseTerragruntConfigFileUsesLogicalPathForPathContext(t *testing.T) {
+	t.Parallel()
+
+	config := `
+include {
+	path = find_in_parent_folders()
+}
+
+inputs = {
+	dir = get_terragrunt_dir()
+}
+`
+
+	// Write the config to a temp dir, which has no parent config, and parse it as if it lived in the fixture folder.
+	tmpDir, err := ioutil.TempDir("", "terragrunt-logical-path")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	physicalPath := filepath.Join(tmpDir, DefaultTerragruntConfigPath)
+	require.NoError(t, ioutil.WriteFile(physicalPath, []byte(config), 0644))
+
+	logicalPath := "../test/fixture-parent-folders/terragrunt-in-root/child/sub-child/sub-sub-child/" + DefaultTerragruntConfigPath
+	opts := mockOptionsForTestWithConfigPath(t, logicalPath)
+
+	terragruntConfig, err := ParseConfigFile(physicalPath, opts, nil)
+	require.NoError(t, err)
+
+	logicalDir, err := filepath.Abs(filepath.Dir(logicalPath))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.ToSlash(logicalDir), terragruntConfig.Inputs["dir"])
+
+	if assert.NotNil(t, terragruntConfig.RemoteState) {
+		assert.Equal(t, "child/sub-child/sub-sub-child/terraform.tfstate", terragruntConfig.RemoteState.Config["key"])
+	}
+}
+
+func TestParseTerragruntConfigFileUsesLogicalPathForFileFunctions(t *testing.T) {
+	t.Parallel()
+
+	config := `
+skip = fileexists("sibling.json")
+
+inputs = {
+	sibling = jsondecode(file("sibling.json"))["name"]
+}
+`
+
+	// sibling.json only exists next to the logical path, so this only parses if file() resolves relative to it.
+	tmpDir, err := ioutil.TempDir("", "terragrunt-logical-path")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	physicalPath := filepath.Join(tmpDir, DefaultTerragruntConfigPath)
+	require.NoError(t, ioutil.WriteFile(physicalPath, []byte(config), 0644))
+
+	logicalPath := "../test/fixture-logical-path/" + DefaultTerragruntConfigPath
+	opts := mockOptionsForTestWithConfigPath(t, logicalPath)
+	opts.LogicalPath = logicalPath
+
+	terragruntConfig, err := ParseConfigFile(physicalPath, opts, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "sibling", terragruntConfig.Inputs["sibling"])
+
+	partialConfig, err := PartialParseConfigFile(physicalPath, opts, nil, []PartialDecodeSectionType{TerragruntFlags})
+	require.NoError(t, err)
+	assert.True(t, partialConfig.Skip)
+}
+
+func TestParseTerragruntConfigFileWithLogicalPathOfAnotherFormat(t *testing.T) {
+	t.Parallel()
+
+	tmpDir, err := ioutil.TempDir("", "terragrunt-logical-path")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	logicalPath := "../test/fixture-logical-path/" + DefaultTerragruntConfigPath
+	opts := mockOptionsForTestWithConfigPath(t, logicalPath)
+	opts.LogicalPath = logicalPath
+
+	// The copy is JSON while the logical path is HCL, so the parser has to be chosen from the file that is read.
+	physicalPath := filepath.Join(tmpDir, DefaultTerragruntJsonConfigPath)
+	require.NoError(t, ioutil.WriteFile(physicalPath, []byte(`{"inputs": {"sibling": "${jsondecode(file(\"sibling.json\"))[\"name\"]}"}}`), 0644))
+
+	terragruntConfig, err := ParseConfigFile(physicalPath, opts, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "sibling", terragruntConfig.Inputs["sibling"])
+
+	// Errors name the file that was read.
+	brokenPath := filepath.Join(tmpDir, "broken", DefaultTerragruntJsonConfigPath)
+	require.NoError(t, os.MkdirAll(filepath.Dir(brokenPath), 0755))
+	require.NoError(t, ioutil.WriteFile(brokenPath, []byte(`{"inputs": `), 0644))
+
+	_, err = ParseConfigFile(brokenPath, opts, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), brokenPath)
+}
+
+func TestParseTerragruntConfigFileWithByteOrderMark(t *testing.T) {
+	t.Parallel()
+
+	tmpDir, err := ioutil.TempDir("", "terragrunt-bom")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	configPath := filepath.Join(tmpDir, DefaultTerragruntConfigPath)
+	require.NoError(t, ioutil.WriteFile(configPath, []byte("\xEF\xBB\xBFinputs = {\n  foo = \"bar\"\n}\n"), 0644))
+
+	terragruntConfig, err := ParseConfigFile(configPath, mockOptionsForTestWithConfigPath(t, configPath), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "bar", terragruntConfig.Inputs["foo"])
+}
+
+func TestParseTerragruntConfigFileUtf16(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		byteOrderMark []byte
+	}{
+		// UTF-16LE, with its byte order mark, as written by e.g. PowerShell's Out-File
+		{"with-bom", []byte{0xFF, 0xFE}},
+		// UTF-16LE without a byte order mark, which is otherwise valid UTF-8 as the config is all ASCII
+		{"without-bom", []byte{}},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			tmpDir, err := ioutil.TempDir("", "terragrunt-utf16")
+			require.NoError(t, err)
+			defer os.RemoveAll(tmpDir)
+			configPath := filepath.Join(tmpDir, DefaultTerragruntConfigPath)
+
+			utf16Config := append([]byte{}, testCase.byteOrderMark...)
+			for _, char := range "inputs = {}\n" {
+				utf16Config = append(utf16Config, byte(char), 0x00)
+			}
+			require.NoError(t, ioutil.WriteFile(configPath, utf16Config, 0644))
+
+			_, err = ParseConfigFile(configPath, mockOptionsForTestWithConfigPath(t, configPath), nil)
+			require.Error(t, err)
+			assert.Equal(t, UnsupportedEncodingError{Filename: configPath}, errors.Unwrap(err))
+
+			_, err = PartialParseConfigFile(configPath, mockOptionsForTestWithConfigPath(t, configPath), nil, []PartialDecodeSectionType{DependenciesBlock})
+			require.Error(t, err)
+			assert.Equal(t, UnsupportedEncodingError{Filename: configPath}, errors.Unwrap(err))
+		})
+	}
+}
+
+func TestParseTerragruntConfigWarnOnEmptyInclude(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name               string
+		configPath         string
+		warnOnEmptyInclude bool
+		expectWarning      bool
+	}{
+		{"EmptyParent", "../test/fixture-parent-folders/empty-parent/child/" + DefaultTerragruntConfigPath, true, true},
+		{"EmptyParentWarningDisabled", "../test/fixture-parent-folders/empty-parent/child/" + DefaultTerragruntConfigPath, false, false},
+		{"NonEmptyParent", "../test/fixture-parent-folders/terragrunt-in-root/child/sub-child/sub-sub-child/" + DefaultTerragruntConfigPath, true, false},
+	}
+
+	for _, testCase := range testCases {
+		// Capture range variable so that it is brought into the scope within the for loop, so that it is stable even
+		// when subtests are run in parallel.
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			var logs bytes.Buffer
+			opts := mockOptionsForTestWithConfigPath(t, testCase.configPath)
+			opts.Logger = util.CreateLoggerWithWriter(&logs, "")
+			opts.WarnOnEmptyInclude = testCase.warnOnEmptyInclude
+
+			_, err := ParseConfigFile(testCase.configPath, opts, nil)
+			require.NoError(t, err)
+
+			warning := "does not set anything that is merged into the including config"
+			if testCase.expectWarning {
+				assert.Contains(t, logs.String(), warning)
+			} else {
+				assert.NotContains(t, logs.String(), warning)
+			}
+		})
+	}
+}
+
+func TestParseTerragruntConfigFileWithSuppliedInclude(t *testing.T) {
+	t.Parallel()
+
+	// Parse the parent directly, as it is parsed when merging, with the include config of the child.
+	childPath := "../test/fixture-parent-folders/terragrunt-in-root/child/sub-child/sub-sub-child/" + DefaultTerragruntConfigPath
+	parentPath := "../test/fixture-parent-folders/terragrunt-in-root/" + DefaultTerragruntConfigPath
+	include := &IncludeConfig{Path: "../../../" + DefaultTerragruntConfigPath}
+
+	terragruntConfig, err := ParseConfigFile(parentPath, mockOptionsForTestWithConfigPath(t, childPath), include)
+	require.NoError(t, err)
+
+	if assert.NotNil(t, terragruntConfig.RemoteState) {
+		assert.Equal(t, "child/sub-child/sub-sub-child/terraform.tfstate", terragruntConfig.RemoteState.Config["key"])
+	}
+}
+
+func TestParseTerragruntConfigIncludeFromModuleCache(t *testing.T) {
+	t.Parallel()
+
+	// Simulate a parent config that lives in a module downloaded by go-getter into the terragrunt cache
+	tmpDir, err := ioutil.TempDir("", "terragrunt-include-module-cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	parentDir := filepath.Join(tmpDir, options.TerragruntCacheDir, "Qv2nDgYLPHcQg1AR0wtsw0n8RqY", "modules", "common")
+	require.NoError(t, os.MkdirAll(parentDir, 0755))
+	parentConfig := `
+inputs = {
+  from_parent   = "parent"
+  relative_path = path_relative_to_include()
+}
+`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(parentDir, DefaultTerragruntConfigPath), []byte(parentConfig), 0644))
+
+	childDir := filepath.Join(tmpDir, "live", "app")
+	require.NoError(t, os.MkdirAll(childDir, 0755))
+	childPath := filepath.Join(childDir, DefaultTerragruntConfigPath)
+
+	testCases := []struct {
+		name        string
+		includePath string
+	}{
+		{"RelativePath", "../../" + options.TerragruntCacheDir + "/Qv2nDgYLPHcQg1AR0wtsw0n8RqY/modules/common/" + DefaultTerragruntConfigPath},
+		{"AbsolutePath", filepath.ToSlash(filepath.Join(parentDir, DefaultTerragruntConfigPath))},
+	}
+
+	for _, testCase := range testCases {
+		config := fmt.Sprintf("include {\n  path = %q\n}\n", testCase.includePath)
+
+		terragruntConfig, err := ParseConfigString(config, mockOptionsForTestWithConfigPath(t, childPath), nil, childPath)
+		require.NoError(t, err, testCase.name)
+		assert.Equal(t, "parent", terragruntConfig.Inputs["from_parent"], testCase.name)
+		assert.Equal(t, "../../../../live/app", terragruntConfig.Inputs["relative_path"], testCase.name)
+	}
+}
+
 func TestParseTerragruntConfigIncludeOverrideRemote(t *testing.T) {
 	t.Parallel()
 
@@ -704,6 +992,16 @@ func TestMergeConfigIntoIncludedConfig(t *testing.T) {
 			&TerragruntConfig{IamRole: "role1"},
 			&TerragruntConfig{IamRole: "role2"},
 		},
+		{
+			&TerragruntConfig{GenerateConfigs: map[string]codegen.GenerateConfig{"provider": codegen.GenerateConfig{Path: "child.tf"}}},
+			&TerragruntConfig{GenerateConfigs: map[string]codegen.GenerateConfig{"backend": codegen.GenerateConfig{Path: "parent.tf"}}},
+			&TerragruntConfig{GenerateConfigs: map[string]codegen.GenerateConfig{"backend": codegen.GenerateConfig{Path: "parent.tf"}, "provider": codegen.GenerateConfig{Path: "child.tf"}}},
+		},
+		{
+			&TerragruntConfig{GenerateConfigs: map[string]codegen.GenerateConfig{"provider": codegen.GenerateConfig{Path: "child.tf"}}},
+			&TerragruntConfig{GenerateConfigs: map[string]codegen.GenerateConfig{"provider": codegen.GenerateConfig{Path: "parent.tf"}}},
+			&TerragruntConfig{GenerateConfigs: map[string]codegen.GenerateConfig{"provider": codegen.GenerateConfig{Path: "child.tf"}}},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -714,6 +1012,21 @@ func TestMergeConfigIntoIncludedConfig(t *testing.T) {
 	}
 }
 
+func TestMergeConfigIntoIncludedConfigWithoutGenerateConfigsDoesNotPanic(t *testing.T) {
+	t.Parallel()
+
+	config := &TerragruntConfig{GenerateConfigs: map[string]codegen.GenerateConfig{"provider": {Path: "child.tf"}}}
+	includedConfig := &TerragruntConfig{}
+
+	var actual *TerragruntConfig
+	var err error
+	require.NotPanics(t, func() {
+		actual, err = mergeConfigWithIncludedConfig(config, includedConfig, mockOptionsForTest(t))
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]codegen.GenerateConfig{"provider": {Path: "child.tf"}}, actual.GenerateConfigs)
+}
+
 func TestParseTerragruntConfigTerraformNoSource(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestConvertValuesMapToCtyValIsDeterministic(t *testing.T) {
+	t.Parallel()
+
+	values := map[string]cty.Value{
+		"region": cty.StringVal("us-east-1"),
+		"count":  cty.NumberIntVal(3),
+		"tags":   cty.ObjectVal(map[string]cty.Value{"env": cty.StringVal("dev"), "team": cty.StringVal("infra")}),
+		"zones":  cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+	}
+
+	first, err := convertValuesMapToCtyVal(values)
+	require.NoError(t, err)
+
+	// Map iteration order is randomized, so convert several times to give any ordering dependence a chance to show.
+	for i := 0; i < 10; i++ {
+		actual, err := convertValuesMapToCtyVal(values)
+		require.NoError(t, err)
+		assert.True(t, first.Type().Equals(actual.Type()))
+		assert.True(t, first.RawEquals(actual))
+	}
+}
+
+func TestConvertValuesMapToCtyValEmpty(t *testing.T) {
+	t.Parallel()
+
+	actual, err := convertValuesMapToCtyVal(map[string]cty.Value{})
+	require.NoError(t, err)
+	assert.Equal(t, cty.NilVal, actual)
+}
@@ -46,6 +46,12 @@ func (dependencyConfig Dependency) shouldGetOutputs() bool {
 
 func (dependencyConfig *Dependency) setRenderedOutputs(terragruntOptions *options.TerragruntOptions) error {
 	if (*dependencyConfig).shouldGetOutputs() || shouldReturnMockOutputs(*dependencyConfig, terragruntOptions) {
+		// Use the outputs that were supplied for this dependency, if any, instead of retrieving them.
+		if injectedOutputs, hasInjectedOutputs := terragruntOptions.DependencyOutputs[dependencyConfig.Name]; hasInjectedOutputs {
+			dependencyConfig.RenderedOutputs = &injectedOutputs
+			return nil
+		}
+
 		outputVal, err := getTerragruntOutputIfAppliedElseConfiguredDefault(*dependencyConfig, terragruntOptions)
 		if err != nil {
 			return err
@@ -310,7 +316,9 @@ func getTerragruntOutput(dependencyConfig Dependency, terragruntOptions *options
 	return &convertedOutput, isEmpty, errors.WithStackTrace(err)
 }
 
-// getOutputJsonWithCaching will run terragrunt output on the target config if it is not already cached.
+// getOutputJsonWithCaching will run terragrunt output on the target config if it is not already cached. Only the
+// outputs that were actually retrieved are cached, so mock outputs never end up in the cache. The cache is bypassed
+// entirely if SkipDependencyOutputCache is set.
 func getOutputJsonWithCaching(targetConfig string, terragruntOptions *options.TerragruntOptions) ([]byte, error) {
 	// Acquire synchronization lock to ensure only one instance of output is called per config.
 	rawActualLock, _ := outputLocks.LoadOrStore(targetConfig, &sync.Mutex{})
@@ -323,6 +331,10 @@ func getOutputJsonWithCaching(targetConfig string, terragruntOptions *options.Te
 	// output" log for the dependency.
 	util.Debugf(terragruntOptions.Logger, "Getting output of dependency %s for config %s", targetConfig, terragruntOptions.TerragruntConfigPath)
 
+	if terragruntOptions.SkipDependencyOutputCache {
+		return getTerragruntOutputJson(terragruntOptions, targetConfig)
+	}
+
 	// Look up if we have already run terragrunt output for this target config
 	rawJsonBytes, hasRun := jsonOutputCache.Load(targetConfig)
 	if hasRun {
@@ -1,12 +1,19 @@
 package config
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/gocty"
+
+	"github.com/gruntwork-io/terragrunt/options"
 )
 
 func TestDecodeDependencyBlockMultiple(t *testing.T) {
@@ -36,6 +43,26 @@ dependency "sql" {
 	assert.Equal(t, decoded.Dependencies[1].ConfigPath, "../sql")
 }
 
+func TestDecodeDependencyBlockConfigPathFromLocal(t *testing.T) {
+	t.Parallel()
+
+	config := `
+locals {
+  account = "prod"
+}
+
+dependency "vpc" {
+  config_path = "../${local.account}/vpc"
+}
+`
+	terragruntConfig, err := PartialParseConfigString(config, mockOptionsForTest(t), nil, DefaultTerragruntConfigPath, []PartialDecodeSectionType{DependencyBlock})
+	require.NoError(t, err)
+
+	require.Len(t, terragruntConfig.TerragruntDependencies, 1)
+	assert.Equal(t, "vpc", terragruntConfig.TerragruntDependencies[0].Name)
+	assert.Equal(t, "../prod/vpc", terragruntConfig.TerragruntDependencies[0].ConfigPath)
+}
+
 func TestDecodeNoDependencyBlock(t *testing.T) {
 	t.Parallel()
 
@@ -109,3 +136,86 @@ dependency "hitchhiker" {
 	require.NotNil(t, defaultAllowedCommands)
 	assert.Equal(t, *defaultAllowedCommands, []string{"validate", "apply"})
 }
+
+func TestGetOutputJsonWithCaching(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name              string
+		skipCache         bool
+		expectedRunsCount int
+	}{
+		{"cached", false, 1},
+		{"uncached", true, 2},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			// The output cache is global and keyed by config path, so use a fresh target config on every run to avoid
+			// hitting outputs cached by an earlier run (e.g. with -count=2).
+			tmpDir, err := ioutil.TempDir("", "terragrunt-dependency-output-cache")
+			require.NoError(t, err)
+			defer os.RemoveAll(tmpDir)
+			targetConfig := filepath.Join(tmpDir, DefaultTerragruntConfigPath)
+			require.NoError(t, ioutil.WriteFile(targetConfig, []byte("# No remote_state block, so outputs are retrieved by running terragrunt output\n"), 0644))
+			defer jsonOutputCache.Delete(targetConfig)
+
+			runsCount := 0
+			terragruntOptions := mockOptionsForTest(t)
+			terragruntOptions.SkipDependencyOutputCache = testCase.skipCache
+			terragruntOptions.RunTerragrunt = func(opts *options.TerragruntOptions) error {
+				runsCount++
+				_, err := fmt.Fprintf(opts.Writer, `{"run": {"type": "number", "value": %d}}`, runsCount)
+				return err
+			}
+
+			_, err = getOutputJsonWithCaching(targetConfig, terragruntOptions)
+			require.NoError(t, err)
+			actual, err := getOutputJsonWithCaching(targetConfig, terragruntOptions)
+			require.NoError(t, err)
+
+			assert.Equal(t, testCase.expectedRunsCount, runsCount)
+			assert.Equal(t, fmt.Sprintf(`{"run": {"type": "number", "value": %d}}`, testCase.expectedRunsCount), string(actual))
+		})
+	}
+}
+
+func TestParseConfigFileWithInjectedDependencyOutputs(t *testing.T) {
+	t.Parallel()
+
+	configPath := "../test/fixture-dependency-injected-outputs/app/" + DefaultTerragruntConfigPath
+	terragruntOptions := mockOptionsForTestWithConfigPath(t, configPath)
+	terragruntOptions.DependencyOutputs = map[string]cty.Value{
+		"vpc": cty.ObjectVal(map[string]cty.Value{"vpc_id": cty.StringVal("vpc-123")}),
+	}
+
+	terragruntConfig, err := ParseConfigFile(configPath, terragruntOptions, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "vpc-123", terragruntConfig.Inputs["vpc_id"])
+}
+
+func TestParseConfigFileWithInjectedDependencyOutputsDoesNotApplyToNestedDependencies(t *testing.T) {
+	t.Parallel()
+
+	configPath := "../test/fixture-dependency-injected-outputs/nested/" + DefaultTerragruntConfigPath
+	terragruntOptions := mockOptionsForTestWithConfigPath(t, configPath)
+	terragruntOptions.SkipDependencyOutputCache = true
+	terragruntOptions.DependencyOutputs = map[string]cty.Value{
+		"vpc": cty.ObjectVal(map[string]cty.Value{"vpc_id": cty.StringVal("vpc-123")}),
+	}
+	// Retrieving the outputs of the network dependency parses its config, which has its own dependency named vpc.
+	terragruntOptions.RunTerragrunt = func(opts *options.TerragruntOptions) error {
+		terragruntConfig, err := ParseConfigFile(opts.TerragruntConfigPath, opts, nil)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(opts.Writer, `{"vpc_id": {"type": "string", "value": %q}}`, terragruntConfig.Inputs["vpc_id"])
+		return err
+	}
+
+	terragruntConfig, err := ParseConfigFile(configPath, terragruntOptions, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "vpc-123", terragruntConfig.Inputs["vpc_id"])
+	assert.Equal(t, "vpc-network", terragruntConfig.Inputs["network_vpc_id"])
+}
@@ -1,20 +1,26 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
 
 	"github.com/gruntwork-io/terragrunt/errors"
 	"github.com/gruntwork-io/terragrunt/options"
 	"github.com/gruntwork-io/terragrunt/util"
 )
 
-// MaxIter is the maximum number of depth we support in recursively evaluating locals.
-const MaxIter = 1000
+// MaxIter is the default maximum number of depth we support in recursively evaluating locals. This can be changed with
+// the MaxLocalsIterations option.
+const MaxIter = options.DEFAULT_MAX_LOCALS_ITERATIONS
 
 // Detailed error messages in diagnostics returned by parsing locals
 const (
@@ -24,6 +30,12 @@ const (
 
 	// A consistent error message for multiple locals block in terragrunt config (which is currently not supported)
 	multipleLocalsBlockDetail = "Terragrunt currently does not support multiple locals blocks in a single config. Consolidate to a single locals block."
+
+	// A consistent error message for references to the locals block as a whole (e.g. `local` instead of `local.foo`)
+	invalidLocalReferenceDetail = "The locals block can not be referenced as a whole. Reference a single local value by name instead, e.g. local.foo."
+
+	// A consistent error message for index lookups on the locals block (e.g. `local["foo"]` instead of `local.foo`)
+	invalidLocalIndexDetail = "Local values can not be looked up by index. Reference a single local value by name instead, e.g. local.foo instead of local[\"foo\"]."
 )
 
 // Local represents a single local name binding. This holds the unevaluated expression, extracted from the parsed file
@@ -33,6 +45,119 @@ type Local struct {
 	Expr hcl.Expression
 }
 
+// ReadTerragruntConfigLocals parses the Terragrunt config file at the configured path and returns its evaluated locals.
+// Only the include and locals blocks are decoded, so dependency outputs are not retrieved.
+func ReadTerragruntConfigLocals(terragruntOptions *options.TerragruntOptions) (map[string]cty.Value, error) {
+	localsAsCty, _, err := readTerragruntConfigBaseBlocks(terragruntOptions)
+	if err != nil {
+		return nil, err
+	}
+	return localsAsValueMap(localsAsCty), nil
+}
+
+// EvaluateLocals parses the given Terragrunt config string and returns its evaluated locals, without decoding the rest
+// of the config. The config's own include block is decoded so that functions such as path_relative_to_include work. The
+// include passed in is the include from a child config when the given config is itself being included, and may be nil.
+func EvaluateLocals(configString string, terragruntOptions *options.TerragruntOptions, include *IncludeConfig) (map[string]cty.Value, error) {
+	filename := terragruntOptions.TerragruntConfigPath
+	parser := hclparse.NewParser()
+	file, err := parseHcl(parser, configString, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	localsAsCty, _, _, err := DecodeBaseBlocks(terragruntOptions, parser, file, filename, include)
+	if err != nil {
+		return nil, err
+	}
+	return localsAsValueMap(localsAsCty), nil
+}
+
+// EvaluateExpression evaluates the given HCL expression against the Terragrunt config file at the configured path, with
+// the same locals, include and functions that an expression in that config would see. This is useful for evaluating
+// a selection of a config, e.g. in an editor, without running terragrunt.
+func EvaluateExpression(exprSrc string, terragruntOptions *options.TerragruntOptions) (cty.Value, hcl.Diagnostics) {
+	localsAsCty, includeForDecode, err := readTerragruntConfigBaseBlocks(terragruntOptions)
+	if err != nil {
+		return cty.NilVal, errorAsDiagnostics(err)
+	}
+
+	evalCtx := CreateTerragruntEvalContext(
+		terragruntOptions.TerragruntConfigPath,
+		terragruntOptions,
+		EvalContextExtensions{Include: includeForDecode, Locals: localsAsCty},
+	)
+
+	expr, diags := hclsyntax.ParseExpression([]byte(exprSrc), "<expression>", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return cty.NilVal, diags
+	}
+	return expr.Value(evalCtx)
+}
+
+// readTerragruntConfigBaseBlocks parses the Terragrunt config file at the configured path and decodes its base blocks
+// (see DecodeBaseBlocks), returning the evaluated locals and the include config that was used to evaluate them.
+func readTerragruntConfigBaseBlocks(terragruntOptions *options.TerragruntOptions) (*cty.Value, *IncludeConfig, error) {
+	filename := terragruntOptions.TerragruntConfigPath
+	configString, err := readConfigFileAsString(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parser := hclparse.NewParser()
+	file, err := parseHcl(parser, configString, filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	localsAsCty, _, includeForDecode, err := DecodeBaseBlocks(terragruntOptions, parser, file, filename, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return localsAsCty, includeForDecode, nil
+}
+
+// localsAsValueMap converts the locals returned by DecodeBaseBlocks to a map, which is nil if there are no locals.
+func localsAsValueMap(localsAsCty *cty.Value) map[string]cty.Value {
+	if *localsAsCty == cty.NilVal {
+		return nil
+	}
+	return localsAsCty.AsValueMap()
+}
+
+// errorAsDiagnostics returns the diagnostics wrapped in the given error, including those of a local that failed to
+// evaluate, or a single error diagnostic describing it if it does not wrap any.
+func errorAsDiagnostics(err error) hcl.Diagnostics {
+	underlying := errors.Unwrap(err)
+	if localErr, isLocalErr := underlying.(LocalEvaluationError); isLocalErr {
+		underlying = errors.Unwrap(localErr.Err)
+	}
+	if diags, isDiags := underlying.(hcl.Diagnostics); isDiags {
+		return diags
+	}
+	return hcl.Diagnostics{&hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Could not read Terragrunt config",
+		Detail:   err.Error(),
+	}}
+}
+
+// MarshalLocals renders the given evaluated locals as indented JSON. The keys are sorted, and the values keep their
+// types (e.g. numbers are rendered as JSON numbers and not strings), including in nested structures.
+func MarshalLocals(locals map[string]cty.Value) ([]byte, error) {
+	localsAsCty := cty.ObjectVal(locals)
+	jsonBytes, err := ctyjson.Marshal(localsAsCty, localsAsCty.Type())
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, jsonBytes, "", "  "); err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	return out.Bytes(), nil
+}
+
 // evaluateLocalsBlock is a routine to evaluate the locals block in a way to allow references to other locals. This
 // will:
 // - Extract a reference to the locals block from the parsed file
@@ -71,13 +196,22 @@ func evaluateLocalsBlock(
 
 	// Continuously attempt to evaluate the locals until there are no more locals to evaluate, or we can't evaluate
 	// further.
+	maxIter := terragruntOptions.MaxLocalsIterations
+	if maxIter <= 0 {
+		maxIter = MaxIter
+	}
 	evaluatedLocals := map[string]cty.Value{}
 	evaluated := true
 	for iterations := 0; len(locals) > 0 && evaluated; iterations++ {
-		if iterations > MaxIter {
-			// Reached maximum supported iterations, which is most likely an infinite loop bug so cut the iteration
-			// short an return an error.
-			return nil, errors.WithStackTrace(MaxIterError{})
+		if iterations >= maxIter {
+			// Reached maximum supported iterations, which is either a reference chain deeper than the configured limit
+			// or an infinite loop bug, so cut the iteration short an return an error.
+			unevaluatedNames := []string{}
+			for _, local := range locals {
+				unevaluatedNames = append(unevaluatedNames, local.Name)
+			}
+			sort.Strings(unevaluatedNames)
+			return nil, errors.WithStackTrace(MaxIterError{Iterations: maxIter, Unevaluated: unevaluatedNames})
 		}
 
 		var err error
@@ -100,12 +234,83 @@ func evaluateLocalsBlock(
 		for _, local := range locals {
 			terragruntOptions.Logger.Printf("\t- %s", local.Name)
 		}
-		return nil, errors.WithStackTrace(CouldNotEvaluateAllLocalsError{})
+		unevaluatedNames := []string{}
+		for _, local := range locals {
+			unevaluatedNames = append(unevaluatedNames, local.Name)
+		}
+		sort.Strings(unevaluatedNames)
+		return nil, errors.WithStackTrace(CouldNotEvaluateAllLocalsError{
+			Unevaluated: unevaluatedNames,
+			Cycle:       findLocalsCycle(terragruntOptions, locals),
+		})
 	}
 
 	return evaluatedLocals, nil
 }
 
+// findLocalsCycle looks for a cycle in the references between the given unevaluated locals. If there is one, this
+// returns the names of the locals in the cycle in reference order, starting and ending with the same local (e.g.
+// [a, b, a]). Otherwise, this returns nil.
+func findLocalsCycle(terragruntOptions *options.TerragruntOptions, locals []*Local) []string {
+	references := map[string][]string{}
+	isUnevaluated := map[string]bool{}
+	names := []string{}
+	for _, local := range locals {
+		names = append(names, local.Name)
+		isUnevaluated[local.Name] = true
+		for _, traversal := range local.Expr.Variables() {
+			if localName := getLocalName(terragruntOptions, traversal); localName != "" {
+				references[local.Name] = append(references[local.Name], localName)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[string]int{}
+	path := []string{}
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		state[name] = visiting
+		path = append(path, name)
+		for _, ref := range references[name] {
+			switch state[ref] {
+			case visiting:
+				for i, pathName := range path {
+					if pathName == ref {
+						return append(append([]string{}, path[i:]...), ref)
+					}
+				}
+			case unvisited:
+				if !isUnevaluated[ref] {
+					// Either evaluated already or undefined, so it can't be part of a cycle.
+					continue
+				}
+				if cycle := visit(ref); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+
+	for _, name := range names {
+		if state[name] == unvisited {
+			if cycle := visit(name); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
 // attemptEvaluateLocals attempts to evaluate the locals block given the map of already evaluated locals, replacing
 // references to locals with the previously evaluated values. This will return:
 // - the list of remaining locals that were unevaluated in this attempt
@@ -157,8 +362,12 @@ func attemptEvaluateLocals(
 		if canEvaluate(terragruntOptions, local.Expr, evaluatedLocals) {
 			evaluatedVal, diags := local.Expr.Value(evalCtx)
 			if diags.HasErrors() {
+				// Point at the replacement of any renamed function, which otherwise fails as an unknown function.
+				diags = append(renamedFunctionDiagnostics(local.Expr), diags...)
 				diagsWriter.WriteDiagnostics(diags)
-				return nil, evaluatedLocals, false, errors.WithStackTrace(diags)
+				return nil, evaluatedLocals, false, errors.WithStackTrace(
+					LocalEvaluationError{Name: local.Name, ConfigPath: filename, Err: diags},
+				)
 			}
 			newEvaluatedLocals[local.Name] = evaluatedVal
 			newlyEvaluatedLocalNames = append(newlyEvaluatedLocalNames, local.Name)
@@ -248,6 +457,18 @@ func getLocalName(terragruntOptions *options.TerragruntOptions, traversal hcl.Tr
 	return ""
 }
 
+// invalidLocalTraversalDetail returns the detail of the diagnostic for a traversal rooted at `local` that does not
+// reference a single local value by name, or empty string if the traversal is valid.
+func invalidLocalTraversalDetail(traversal hcl.Traversal) string {
+	if len(traversal) == 1 {
+		return invalidLocalReferenceDetail
+	}
+	if _, isIndex := traversal[1].(hcl.TraverseIndex); isIndex {
+		return invalidLocalIndexDetail
+	}
+	return ""
+}
+
 // getLocalsBlock takes a parsed HCL file and extracts a reference to the `locals` block, if there is one defined.
 func getLocalsBlock(hclFile *hcl.File) (*hcl.Block, hcl.Diagnostics) {
 	localsSchema := &hcl.BodySchema{
@@ -288,8 +509,16 @@ func decodeLocalsBlock(localsBlock *hcl.Block) ([]*Local, hcl.Diagnostics) {
 		return nil, diags
 	}
 
+	// Go through the locals in order of name, so that they are evaluated, logged and reported in a deterministic order.
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
 	locals := make([]*Local, 0, len(attrs))
-	for name, attr := range attrs {
+	for _, name := range names {
+		attr := attrs[name]
 		if !hclsyntax.ValidIdentifier(name) {
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
@@ -299,6 +528,31 @@ func decodeLocalsBlock(localsBlock *hcl.Block) ([]*Local, hcl.Diagnostics) {
 			})
 		}
 
+		// A local that references itself, the whole locals block or an index on the locals block can never be
+		// evaluated, so point at the reference directly instead of failing later with the generic error for locals
+		// that could not be evaluated.
+		for _, traversal := range attr.Expr.Variables() {
+			localName := getLocalName(nil, traversal)
+			if traversal.RootName() == "local" {
+				if detail := invalidLocalTraversalDetail(traversal); detail != "" {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Invalid local value reference",
+						Detail:   detail,
+						Subject:  traversal.SourceRange().Ptr(),
+					})
+				}
+			}
+			if localName == name {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Self-referencing local value",
+					Detail:   fmt.Sprintf("local.%s references itself.", name),
+					Subject:  traversal.SourceRange().Ptr(),
+				})
+			}
+		}
+
 		locals = append(locals, &Local{
 			Name: name,
 			Expr: attr.Expr,
@@ -311,14 +565,38 @@ func decodeLocalsBlock(localsBlock *hcl.Block) ([]*Local, hcl.Diagnostics) {
 // Custom Errors Returned by Functions in this Code
 // ------------------------------------------------
 
-type CouldNotEvaluateAllLocalsError struct{}
+type CouldNotEvaluateAllLocalsError struct {
+	Unevaluated []string
+	Cycle       []string
+}
 
 func (err CouldNotEvaluateAllLocalsError) Error() string {
-	return "Could not evaluate all locals in block."
+	msg := fmt.Sprintf("Could not evaluate all locals in block. Locals that were not evaluated: %s.", strings.Join(err.Unevaluated, ", "))
+	if len(err.Cycle) > 0 {
+		cycle := []string{}
+		for _, name := range err.Cycle {
+			cycle = append(cycle, "local."+name)
+		}
+		msg = fmt.Sprintf("%s Found a cycle: %s", msg, strings.Join(cycle, " -> "))
+	}
+	return msg
 }
 
-type MaxIterError struct{}
+type LocalEvaluationError struct {
+	Name       string
+	ConfigPath string
+	Err        error
+}
+
+func (err LocalEvaluationError) Error() string {
+	return fmt.Sprintf("Error evaluating local.%s (in %s): %v", err.Name, err.ConfigPath, err.Err)
+}
+
+type MaxIterError struct {
+	Iterations  int
+	Unevaluated []string
+}
 
 func (err MaxIterError) Error() string {
-	return "Maximum iterations reached in attempting to evaluate locals. This is most likely a bug in Terragrunt. Please file an issue on the project: https://github.com/gruntwork-io/terragrunt/issues"
+	return fmt.Sprintf("Maximum iterations (%d) reached in attempting to evaluate locals. Locals that were not evaluated: %s. If the references between locals are deeper than this, increase the limit with the MaxLocalsIterations option. Otherwise, this is most likely a bug in Terragrunt. Please file an issue on the project: https://github.com/gruntwork-io/terragrunt/issues", err.Iterations, strings.Join(err.Unevaluated, ", "))
 }
@@ -1,15 +1,22 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
 	"github.com/zclconf/go-cty/cty/gocty"
 
 	"github.com/gruntwork-io/terragrunt/errors"
+	"github.com/gruntwork-io/terragrunt/util"
 )
 
 func TestEvaluateLocalsBlock(t *testing.T) {
@@ -96,6 +103,159 @@ func TestEvaluateLocalsBlockMultiDeepReference(t *testing.T) {
 	}
 }
 
+func TestDecodeLocalsBlockIsSortedByName(t *testing.T) {
+	t.Parallel()
+
+	parser := hclparse.NewParser()
+	file, err := parseHcl(parser, LocalsTestConfig, "terragrunt.hcl")
+	require.NoError(t, err)
+
+	localsBlock, diags := getLocalsBlock(file)
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	// Decode several times, as the attributes are read from a map with a randomized iteration order.
+	for i := 0; i < 10; i++ {
+		locals, diags := decodeLocalsBlock(localsBlock)
+		require.False(t, diags.HasErrors(), diags.Error())
+
+		actualNames := []string{}
+		for _, local := range locals {
+			actualNames = append(actualNames, local.Name)
+		}
+		assert.Equal(t, []string{"bar", "foo", "region", "s3_url", "x", "y", "z"}, actualNames)
+	}
+}
+
+func TestEvaluateLocalsBlockLogsLocalsInOrderOfName(t *testing.T) {
+	t.Parallel()
+
+	// Evaluate several times, as the attributes are read from a map with a randomized iteration order.
+	for i := 0; i < 10; i++ {
+		var logs bytes.Buffer
+		terragruntOptions := mockOptionsForTest(t)
+		terragruntOptions.Logger = util.CreateLoggerWithWriter(&logs, "")
+		mockFilename := "terragrunt.hcl"
+
+		parser := hclparse.NewParser()
+		file, err := parseHcl(parser, LocalsTestUnsortedCycleConfig, mockFilename)
+		require.NoError(t, err)
+
+		_, err = evaluateLocalsBlock(terragruntOptions, parser, file, mockFilename, nil)
+		require.Error(t, err)
+
+		loggedNames := []string{}
+		for _, line := range strings.Split(logs.String(), "\n") {
+			if idx := strings.Index(line, "\t- "); idx >= 0 {
+				loggedNames = append(loggedNames, line[idx+len("\t- "):])
+			}
+		}
+		assert.Equal(t, []string{"alpha", "mid", "zeta"}, loggedNames)
+	}
+}
+
+func TestEvaluateLocalsBlockMaxIterations(t *testing.T) {
+	t.Parallel()
+
+	terragruntOptions := mockOptionsForTest(t)
+	terragruntOptions.MaxLocalsIterations = 3
+	mockFilename := "terragrunt.hcl"
+
+	parser := hclparse.NewParser()
+	file, err := parseHcl(parser, LocalsTestMultiDeepReferenceConfig, mockFilename)
+	require.NoError(t, err)
+
+	_, err = evaluateLocalsBlock(terragruntOptions, parser, file, mockFilename, nil)
+	require.Error(t, err)
+
+	maxIterErr, isMaxIterErr := errors.Unwrap(err).(MaxIterError)
+	require.True(t, isMaxIterErr, "Did not get expected error: %s", err)
+	assert.Equal(t, 3, maxIterErr.Iterations)
+	assert.Equal(t, []string{"d", "e", "f", "g", "h", "i", "j"}, maxIterErr.Unevaluated)
+}
+
+func TestEvaluateLocalsBlockConditionalReferences(t *testing.T) {
+	t.Parallel()
+
+	terragruntOptions := mockOptionsForTest(t)
+	mockFilename := "terragrunt.hcl"
+
+	parser := hclparse.NewParser()
+	file, err := parseHcl(parser, LocalsTestConditionalConfig, mockFilename)
+	require.NoError(t, err)
+
+	// The conditional must only be evaluated once the condition and both branches are evaluated, so every reference
+	// has to be reported as a variable of the expression.
+	envAttr := file.Body.(*hclsyntax.Body).Blocks[0].Body.Attributes["env"]
+	require.NotNil(t, envAttr)
+	assert.Len(t, envAttr.Expr.Variables(), 3)
+
+	evaluatedLocals, err := evaluateLocalsBlock(terragruntOptions, parser, file, mockFilename, nil)
+	require.NoError(t, err)
+
+	var actualEnv string
+	require.NoError(t, gocty.FromCtyValue(evaluatedLocals["env"], &actualEnv))
+	assert.Equal(t, "development", actualEnv)
+}
+
+func TestGetLocalName(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		expr         string
+		expectedName string
+	}{
+		{"local.foo", "foo"},
+		{"local.foo.bar.baz", "foo"},
+		{"local.items[0]", "items"},
+		{`local.tags["env"]`, "tags"},
+		{"local.items[*].name", "items"},
+		{"local.items[0].name", "items"},
+		{"local", ""},
+		{"var.foo", ""},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.expr, func(t *testing.T) {
+			expr, diags := hclsyntax.ParseExpression([]byte(testCase.expr), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+			require.False(t, diags.HasErrors(), diags.Error())
+
+			vars := expr.Variables()
+			require.Len(t, vars, 1)
+			assert.Equal(t, testCase.expectedName, getLocalName(nil, vars[0]))
+		})
+	}
+}
+
+func TestEvaluateLocalsBlockIndexAndSplatReferences(t *testing.T) {
+	t.Parallel()
+
+	terragruntOptions := mockOptionsForTest(t)
+	mockFilename := "terragrunt.hcl"
+
+	parser := hclparse.NewParser()
+	file, err := parseHcl(parser, LocalsTestIndexAndSplatConfig, mockFilename)
+	require.NoError(t, err)
+
+	evaluatedLocals, err := evaluateLocalsBlock(terragruntOptions, parser, file, mockFilename, nil)
+	require.NoError(t, err)
+
+	var actualFirst string
+	require.NoError(t, gocty.FromCtyValue(evaluatedLocals["first"], &actualFirst))
+	assert.Equal(t, "a", actualFirst)
+
+	var actualEnv string
+	require.NoError(t, gocty.FromCtyValue(evaluatedLocals["env"], &actualEnv))
+	assert.Equal(t, "dev", actualEnv)
+
+	expectedNames := cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")})
+	assert.True(t, expectedNames.RawEquals(evaluatedLocals["names"]), "Unexpected names: %s", evaluatedLocals["names"].GoString())
+
+	var actualSize float64
+	require.NoError(t, gocty.FromCtyValue(evaluatedLocals["size"], &actualSize))
+	assert.Equal(t, float64(2), actualSize)
+}
+
 func TestEvaluateLocalsBlockImpossibleWillFail(t *testing.T) {
 	t.Parallel()
 
@@ -109,13 +269,97 @@ func TestEvaluateLocalsBlockImpossibleWillFail(t *testing.T) {
 	_, err = evaluateLocalsBlock(terragruntOptions, parser, file, mockFilename, nil)
 	require.Error(t, err)
 
-	switch errors.Unwrap(err).(type) {
+	switch unwrapped := errors.Unwrap(err).(type) {
 	case CouldNotEvaluateAllLocalsError:
+		assert.Equal(t, []string{"a", "b"}, unwrapped.Unevaluated)
+		assert.Equal(t, []string{"a", "b", "a"}, unwrapped.Cycle)
+		assert.Contains(t, err.Error(), "local.a -> local.b -> local.a")
 	default:
 		t.Fatalf("Did not get expected error: %s", err)
 	}
 }
 
+func TestEvaluateLocalsBlockReportsCycleBehindDependentLocal(t *testing.T) {
+	t.Parallel()
+
+	terragruntOptions := mockOptionsForTest(t)
+	mockFilename := "terragrunt.hcl"
+
+	parser := hclparse.NewParser()
+	file, err := parseHcl(parser, LocalsTestCycleBehindDependentConfig, mockFilename)
+	require.NoError(t, err)
+
+	_, err = evaluateLocalsBlock(terragruntOptions, parser, file, mockFilename, nil)
+	require.Error(t, err)
+
+	unwrapped, isCouldNotEvaluate := errors.Unwrap(err).(CouldNotEvaluateAllLocalsError)
+	require.True(t, isCouldNotEvaluate, "Did not get expected error: %s", err)
+	assert.Equal(t, []string{"x", "y", "z"}, unwrapped.Unevaluated)
+	assert.Equal(t, []string{"y", "z", "y"}, unwrapped.Cycle)
+}
+
+func TestEvaluateLocalsBlockSelfReference(t *testing.T) {
+	t.Parallel()
+
+	terragruntOptions := mockOptionsForTest(t)
+	mockFilename := "terragrunt.hcl"
+
+	parser := hclparse.NewParser()
+	file, err := parseHcl(parser, LocalsTestSelfReferenceConfig, mockFilename)
+	require.NoError(t, err)
+
+	_, err = evaluateLocalsBlock(terragruntOptions, parser, file, mockFilename, nil)
+	require.Error(t, err)
+
+	diags, isDiags := errors.Unwrap(err).(hcl.Diagnostics)
+	require.True(t, isDiags, "Did not get expected error: %s", err)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "local.x references itself.", diags[0].Detail)
+	assert.Equal(t, 4, diags[0].Subject.Start.Line)
+}
+
+func TestEvaluateLocalsBlockBareLocalReference(t *testing.T) {
+	t.Parallel()
+
+	terragruntOptions := mockOptionsForTest(t)
+	mockFilename := "terragrunt.hcl"
+
+	parser := hclparse.NewParser()
+	file, err := parseHcl(parser, LocalsTestBareReferenceConfig, mockFilename)
+	require.NoError(t, err)
+
+	_, err = evaluateLocalsBlock(terragruntOptions, parser, file, mockFilename, nil)
+	require.Error(t, err)
+
+	diags, isDiags := errors.Unwrap(err).(hcl.Diagnostics)
+	require.True(t, isDiags, "Did not get expected error: %s", err)
+	require.Len(t, diags, 1)
+	assert.Equal(t, invalidLocalReferenceDetail, diags[0].Detail)
+	assert.Equal(t, 4, diags[0].Subject.Start.Line)
+	assert.Equal(t, 7, diags[0].Subject.Start.Column)
+}
+
+func TestEvaluateLocalsBlockIndexLocalReference(t *testing.T) {
+	t.Parallel()
+
+	terragruntOptions := mockOptionsForTest(t)
+	mockFilename := "terragrunt.hcl"
+
+	parser := hclparse.NewParser()
+	file, err := parseHcl(parser, LocalsTestIndexReferenceConfig, mockFilename)
+	require.NoError(t, err)
+
+	_, err = evaluateLocalsBlock(terragruntOptions, parser, file, mockFilename, nil)
+	require.Error(t, err)
+
+	diags, isDiags := errors.Unwrap(err).(hcl.Diagnostics)
+	require.True(t, isDiags, "Did not get expected error: %s", err)
+	require.Len(t, diags, 1)
+	assert.Equal(t, invalidLocalIndexDetail, diags[0].Detail)
+	assert.Equal(t, 4, diags[0].Subject.Start.Line)
+	assert.Equal(t, 7, diags[0].Subject.Start.Column)
+}
+
 func TestEvaluateLocalsBlockMultipleLocalsBlocksWillFail(t *testing.T) {
 	t.Parallel()
 
@@ -130,6 +374,278 @@ func TestEvaluateLocalsBlockMultipleLocalsBlocksWillFail(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestEvaluateLocalsBlockJsonRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	terragruntOptions := mockOptionsForTest(t)
+	// file() reads relative to the config file, so put the config next to the json file in the fixture folder.
+	mockFilename := "../test/fixture-locals/json-config/terragrunt.hcl"
+
+	parser := hclparse.NewParser()
+	file, err := parseHcl(parser, LocalsTestJsonRoundTripConfig, mockFilename)
+	require.NoError(t, err)
+
+	evaluatedLocals, err := evaluateLocalsBlock(terragruntOptions, parser, file, mockFilename, nil)
+	require.NoError(t, err)
+
+	var actualKey string
+	require.NoError(t, gocty.FromCtyValue(evaluatedLocals["key"], &actualKey))
+	assert.Equal(t, "value", actualKey)
+
+	var actualRegion string
+	require.NoError(t, gocty.FromCtyValue(evaluatedLocals["first_region"], &actualRegion))
+	assert.Equal(t, "us-east-1", actualRegion)
+
+	var actualEncoded string
+	require.NoError(t, gocty.FromCtyValue(evaluatedLocals["encoded"], &actualEncoded))
+	assert.Equal(t, `{"key":"value"}`, actualEncoded)
+}
+
+func TestEvaluateLocalsBlockErrorIdentifiesLocal(t *testing.T) {
+	t.Parallel()
+
+	terragruntOptions := mockOptionsForTest(t)
+	mockFilename := "terragrunt.hcl"
+
+	parser := hclparse.NewParser()
+	file, err := parseHcl(parser, LocalsTestEvaluationErrorConfig, mockFilename)
+	require.NoError(t, err)
+
+	_, err = evaluateLocalsBlock(terragruntOptions, parser, file, mockFilename, nil)
+	require.Error(t, err)
+
+	evaluationErr, isLocalEvaluationError := errors.Unwrap(err).(LocalEvaluationError)
+	require.True(t, isLocalEvaluationError, "Did not get expected error: %s", err)
+	assert.Equal(t, "broken", evaluationErr.Name)
+	assert.Equal(t, mockFilename, evaluationErr.ConfigPath)
+	assert.Contains(t, err.Error(), "local.broken (in terragrunt.hcl)")
+}
+
+func TestMarshalLocals(t *testing.T) {
+	t.Parallel()
+
+	terragruntOptions := mockOptionsForTest(t)
+	mockFilename := "terragrunt.hcl"
+
+	parser := hclparse.NewParser()
+	file, err := parseHcl(parser, LocalsTestMarshalConfig, mockFilename)
+	require.NoError(t, err)
+
+	evaluatedLocals, err := evaluateLocalsBlock(terragruntOptions, parser, file, mockFilename, nil)
+	require.NoError(t, err)
+
+	actual, err := MarshalLocals(evaluatedLocals)
+	require.NoError(t, err)
+
+	expected := `{
+  "count": 3,
+  "enabled": true,
+  "regions": [
+    "us-east-1",
+    "eu-west-1"
+  ],
+  "tags": {
+    "name": "app",
+    "nested": {
+      "ports": [
+        80,
+        443
+      ]
+    }
+  }
+}`
+	assert.Equal(t, expected, string(actual))
+}
+
+func TestMarshalLocalsEmpty(t *testing.T) {
+	t.Parallel()
+
+	actual, err := MarshalLocals(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "{}", string(actual))
+}
+
+func TestEvaluateLocalsBlockRenamedFunction(t *testing.T) {
+	t.Parallel()
+
+	terragruntOptions := mockOptionsForTest(t)
+	mockFilename := "terragrunt.hcl"
+
+	parser := hclparse.NewParser()
+	file, err := parseHcl(parser, LocalsTestRenamedFunctionConfig, mockFilename)
+	require.NoError(t, err)
+
+	_, err = evaluateLocalsBlock(terragruntOptions, parser, file, mockFilename, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "The function get_tfvars_dir has been renamed to get_terragrunt_dir")
+}
+
+func TestEvaluateLocalsBlockWithMockedAwsAccountId(t *testing.T) {
+	t.Parallel()
+
+	terragruntOptions := mockOptionsForTest(t)
+	terragruntOptions.AllowExtraFunctionOverrides = true
+	terragruntOptions.ExtraFunctions = map[string]function.Function{
+		"get_aws_account_id": function.New(&function.Spec{
+			Type: function.StaticReturnType(cty.String),
+			Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+				return cty.StringVal("123456789012"), nil
+			},
+		}),
+	}
+	mockFilename := "terragrunt.hcl"
+
+	parser := hclparse.NewParser()
+	file, err := parseHcl(parser, LocalsTestAwsAccountIdConfig, mockFilename)
+	require.NoError(t, err)
+
+	evaluatedLocals, err := evaluateLocalsBlock(terragruntOptions, parser, file, mockFilename, nil)
+	require.NoError(t, err)
+
+	var actualPrefix string
+	require.NoError(t, gocty.FromCtyValue(evaluatedLocals["account_prefix"], &actualPrefix))
+	assert.Equal(t, "acct-123456789012", actualPrefix)
+}
+
+func TestEvaluateLocalsBlockWithExtraFunction(t *testing.T) {
+	t.Parallel()
+
+	terragruntOptions := mockOptionsForTest(t)
+	terragruntOptions.ExtraFunctions = map[string]function.Function{
+		"fetch_secret": function.New(&function.Spec{
+			Params: []function.Parameter{{Name: "name", Type: cty.String}},
+			Type:   function.StaticReturnType(cty.String),
+			Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+				return cty.StringVal("secret-" + args[0].AsString()), nil
+			},
+		}),
+	}
+	mockFilename := "terragrunt.hcl"
+
+	parser := hclparse.NewParser()
+	file, err := parseHcl(parser, LocalsTestExtraFunctionConfig, mockFilename)
+	require.NoError(t, err)
+
+	evaluatedLocals, err := evaluateLocalsBlock(terragruntOptions, parser, file, mockFilename, nil)
+	require.NoError(t, err)
+
+	var actualPassword string
+	require.NoError(t, gocty.FromCtyValue(evaluatedLocals["db_password"], &actualPassword))
+	assert.Equal(t, "secret-db", actualPassword)
+}
+
+func TestEvaluateLocalsBlockExtraFunctionCollidesWithBuiltin(t *testing.T) {
+	t.Parallel()
+
+	terragruntOptions := mockOptionsForTest(t)
+	terragruntOptions.ExtraFunctions = map[string]function.Function{
+		"get_aws_account_id": function.New(&function.Spec{
+			Type: function.StaticReturnType(cty.String),
+			Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+				return cty.StringVal("123456789012"), nil
+			},
+		}),
+	}
+	mockFilename := "terragrunt.hcl"
+
+	parser := hclparse.NewParser()
+	file, err := parseHcl(parser, LocalsTestAwsAccountIdConfig, mockFilename)
+	require.NoError(t, err)
+
+	_, _, _, err = DecodeBaseBlocks(terragruntOptions, parser, file, mockFilename, nil)
+	require.Error(t, err)
+	assert.IsType(t, ExtraFunctionOverridesBuiltin{}, errors.Unwrap(err))
+}
+
+func TestEvaluateLocalsBlockTryNestedAccess(t *testing.T) {
+	t.Parallel()
+
+	terragruntOptions := mockOptionsForTest(t)
+	mockFilename := "terragrunt.hcl"
+
+	parser := hclparse.NewParser()
+	file, err := parseHcl(parser, LocalsTestTryNestedAccessConfig, mockFilename)
+	require.NoError(t, err)
+
+	evaluatedLocals, err := evaluateLocalsBlock(terragruntOptions, parser, file, mockFilename, nil)
+	require.NoError(t, err)
+
+	var actualZone string
+	require.NoError(t, gocty.FromCtyValue(evaluatedLocals["zone"], &actualZone))
+	assert.Equal(t, "us-east-1a", actualZone)
+
+	var actualSubnet string
+	require.NoError(t, gocty.FromCtyValue(evaluatedLocals["subnet"], &actualSubnet))
+	assert.Equal(t, "default", actualSubnet)
+}
+
+func TestEvaluateLocals(t *testing.T) {
+	t.Parallel()
+
+	evaluatedLocals, err := EvaluateLocals(LocalsTestConfig, mockOptionsForTest(t), nil)
+	require.NoError(t, err)
+
+	var actualS3Url string
+	require.NoError(t, gocty.FromCtyValue(evaluatedLocals["s3_url"], &actualS3Url))
+	assert.Equal(t, "com.amazonaws.us-east-1.s3", actualS3Url)
+
+	var actualZ float64
+	require.NoError(t, gocty.FromCtyValue(evaluatedLocals["z"], &actualZ))
+	assert.Equal(t, float64(3), actualZ)
+}
+
+func TestEvaluateLocalsWithInclude(t *testing.T) {
+	t.Parallel()
+
+	configPath := "../test/fixture-locals/path-relative-to-include/child/" + DefaultTerragruntConfigPath
+	terragruntOptions := terragruntOptionsForTest(t, configPath)
+	configString, err := readConfigFileAsString(configPath)
+	require.NoError(t, err)
+
+	evaluatedLocals, err := EvaluateLocals(configString, terragruntOptions, nil)
+	require.NoError(t, err)
+
+	var actualRelativePath string
+	require.NoError(t, gocty.FromCtyValue(evaluatedLocals["relative_path"], &actualRelativePath))
+	assert.Equal(t, "child", actualRelativePath)
+}
+
+func TestEvaluateExpression(t *testing.T) {
+	t.Parallel()
+
+	terragruntOptions := terragruntOptionsForTest(t, "../test/fixture-locals/evaluate-expression/terragrunt.hcl")
+
+	actual, diags := EvaluateExpression(`"${local.region}-${local.name}"`, terragruntOptions)
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	var actualName string
+	require.NoError(t, gocty.FromCtyValue(actual, &actualName))
+	assert.Equal(t, "us-east-1-app", actualName)
+}
+
+func TestEvaluateExpressionUndefinedLocal(t *testing.T) {
+	t.Parallel()
+
+	terragruntOptions := terragruntOptionsForTest(t, "../test/fixture-locals/evaluate-expression/terragrunt.hcl")
+
+	_, diags := EvaluateExpression("local.zone", terragruntOptions)
+	require.True(t, diags.HasErrors())
+	assert.Contains(t, diags.Error(), "zone")
+}
+
+func TestEvaluateExpressionLocalEvaluationError(t *testing.T) {
+	t.Parallel()
+
+	terragruntOptions := terragruntOptionsForTest(t, "../test/fixture-locals/evaluate-expression-local-error/terragrunt.hcl")
+
+	_, diags := EvaluateExpression("local.region", terragruntOptions)
+	require.True(t, diags.HasErrors())
+	require.Len(t, diags, 1)
+	assert.Equal(t, "Invalid function argument", diags[0].Summary)
+	require.NotNil(t, diags[0].Subject)
+	assert.Equal(t, 3, diags[0].Subject.Start.Line)
+}
+
 type Foo struct {
 	Region string `cty:"region"`
 	Foo    string `cty:"foo"`
@@ -181,6 +697,67 @@ locals {
 }
 `
 
+const LocalsTestCycleBehindDependentConfig = `
+locals {
+  x = local.y
+  y = local.z
+  z = local.y
+}
+`
+
+const LocalsTestUnsortedCycleConfig = `
+locals {
+  zeta  = local.alpha
+  alpha = local.mid
+  mid   = local.zeta
+}
+`
+
+const LocalsTestConditionalConfig = `
+locals {
+  env = local.is_prod ? local.prod_name : local.dev_name
+
+  is_prod   = local.env_name == "prod"
+  env_name  = "dev"
+  prod_name = "production"
+  dev_name  = "development"
+}
+`
+
+const LocalsTestIndexAndSplatConfig = `
+locals {
+  first = local.items[0].name
+  env   = local.tags["env"]
+  names = local.items[*].name
+  size  = local.cluster.nodes.count
+
+  items   = [{ name = "a" }, { name = "b" }]
+  tags    = { env = "dev" }
+  cluster = { nodes = { count = 2 } }
+}
+`
+
+const LocalsTestSelfReferenceConfig = `
+locals {
+  y = 1
+  x = local.x + local.y
+}
+`
+
+const LocalsTestBareReferenceConfig = `
+locals {
+  y = 1
+  x = local
+}
+`
+
+const LocalsTestIndexReferenceConfig = `
+locals {
+  y = 1
+  x = local["y"]
+}
+`
+
 const MultipleLocalsBlockConfig = `
 locals {
   a = "a"
@@ -190,3 +767,61 @@ locals {
   b = "b"
 }
 `
+
+const LocalsTestJsonRoundTripConfig = `
+locals {
+  config       = jsondecode(file("config.json"))
+  key          = local.config["key"]
+  first_region = local.config.regions[0]
+  encoded      = jsonencode({ key = local.key })
+}
+`
+
+const LocalsTestEvaluationErrorConfig = `
+locals {
+  region = "us-east-1"
+  broken = local.region + 1
+}
+`
+
+const LocalsTestMarshalConfig = `
+locals {
+  tags = {
+    name   = "app"
+    nested = { ports = [80, 443] }
+  }
+  regions = ["us-east-1", "eu-west-1"]
+  enabled = true
+  count   = 3
+}
+`
+
+const LocalsTestRenamedFunctionConfig = `
+locals {
+  config_path = "${get_tfvars_dir()}/config.json"
+}
+`
+
+const LocalsTestAwsAccountIdConfig = `
+locals {
+  account_prefix = "acct-${get_aws_account_id()}"
+}
+`
+
+const LocalsTestExtraFunctionConfig = `
+locals {
+  db_password = fetch_secret("db")
+}
+`
+
+const LocalsTestTryNestedAccessConfig = `
+locals {
+  // Both reference local.network, which must be evaluated first
+  zone   = try(local.network.zone, "default")
+  subnet = try(local.network.subnet, "default")
+
+  network = {
+    zone = "us-east-1a"
+  }
+}
+`
@@ -12,6 +12,8 @@ import (
 	"github.com/gruntwork-io/terragrunt/errors"
 	"github.com/gruntwork-io/terragrunt/util"
 	"github.com/hashicorp/go-version"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
 )
 
 var TERRAFORM_COMMANDS_WITH_SUBCOMMAND = []string{
@@ -22,6 +24,9 @@ var TERRAFORM_COMMANDS_WITH_SUBCOMMAND = []string{
 
 const DEFAULT_MAX_FOLDERS_TO_CHECK = 100
 
+// the maximum number of passes over a locals block, which bounds how deep references between locals can be
+const DEFAULT_MAX_LOCALS_ITERATIONS = 1000
+
 // no limits on parallelism by default (limited by GOPROCS)
 const DEFAULT_PARALLELISM = math.MaxInt32
 
@@ -37,6 +42,11 @@ type TerragruntOptions struct {
 	// Location of the Terragrunt config file
 	TerragruntConfigPath string
 
+	// The path that the config at TerragruntConfigPath logically lives at, if it is parsed from a copy somewhere else
+	// (e.g. in a temp dir). If set, relative paths in functions such as file and templatefile are resolved against it
+	// instead of the path of the copy. It does not apply to included configs, and Clone does not copy it.
+	LogicalPath string
+
 	// Version of terragrunt
 	TerragruntVersion *version.Version
 
@@ -102,6 +112,15 @@ type TerragruntOptions struct {
 	// exposed here primarily so we can set it to a low value at test time.
 	MaxFoldersToCheck int
 
+	// The maximum number of passes to make over a locals block when resolving references between locals. Each pass
+	// evaluates the locals whose references have all been evaluated, so this bounds how deep the references can be.
+	// If zero, DEFAULT_MAX_LOCALS_ITERATIONS is used.
+	MaxLocalsIterations int
+
+	// The name of a marker file (e.g. .terragrunt-root) at which find_in_parent_folders stops searching. The folder
+	// containing the marker is still searched, but none of its parents are. Empty means search up to the root.
+	FindInParentFoldersStopFile string
+
 	// Whether we should automatically run terraform init if necessary when executing other commands
 	AutoRetry bool
 
@@ -146,6 +165,32 @@ type TerragruntOptions struct {
 	// Attributes to override in AWS provider nested within modules as part of the aws-provider-patch command. See that
 	// command for more info.
 	AwsProviderPatchOverrides map[string]string
+
+	// The canonical paths of the configs that are currently being read with read_terragrunt_config, starting with the
+	// outermost config. This is used to detect configs that read each other, which would otherwise recurse forever.
+	ReadTerragruntConfigChain []string
+
+	// If set to true, log a warning when an included config does not set anything that is merged into the including
+	// config. This helps catch include paths that point to the wrong file.
+	WarnOnEmptyInclude bool
+
+	// Additional functions to make available when parsing Terragrunt configs, keyed by function name. A function with
+	// the same name as a built-in function is an error, unless AllowExtraFunctionOverrides is set.
+	ExtraFunctions map[string]function.Function
+
+	// Outputs to use for dependency blocks instead of retrieving them from the target module, keyed by the name of the
+	// dependency block. This only applies to the config parsed with these options and the config it includes: Clone does
+	// not copy it, so the dependencies of a dependency are retrieved as usual, even if they have the same name.
+	// Dependencies that are not in the map are retrieved as usual.
+	DependencyOutputs map[string]cty.Value
+
+	// If set to true, always retrieve the outputs of dependencies instead of reusing outputs that were already
+	// retrieved for the same dependency earlier in the run.
+	SkipDependencyOutputCache bool
+
+	// If set to true, functions in ExtraFunctions replace built-in functions of the same name (e.g. to mock
+	// get_aws_account_id in tests) instead of failing the parse.
+	AllowExtraFunctionOverrides bool
 }
 
 // Create a new TerragruntOptions object with reasonable defaults for real usage
@@ -177,6 +222,7 @@ func NewTerragruntOptions(terragruntConfigPath string) (*TerragruntOptions, erro
 		Writer:                      os.Stdout,
 		ErrWriter:                   os.Stderr,
 		MaxFoldersToCheck:           DEFAULT_MAX_FOLDERS_TO_CHECK,
+		MaxLocalsIterations:         DEFAULT_MAX_LOCALS_ITERATIONS,
 		AutoRetry:                   true,
 		MaxRetryAttempts:            DEFAULT_MAX_RETRY_ATTEMPTS,
 		Sleep:                       DEFAULT_SLEEP,
@@ -250,6 +296,8 @@ func (terragruntOptions *TerragruntOptions) Clone(terragruntConfigPath string) *
 		Writer:                      terragruntOptions.Writer,
 		ErrWriter:                   terragruntOptions.ErrWriter,
 		MaxFoldersToCheck:           terragruntOptions.MaxFoldersToCheck,
+		MaxLocalsIterations:         terragruntOptions.MaxLocalsIterations,
+		FindInParentFoldersStopFile: terragruntOptions.FindInParentFoldersStopFile,
 		AutoRetry:                   terragruntOptions.AutoRetry,
 		MaxRetryAttempts:            terragruntOptions.MaxRetryAttempts,
 		Sleep:                       terragruntOptions.Sleep,
@@ -260,6 +308,11 @@ func (terragruntOptions *TerragruntOptions) Clone(terragruntConfigPath string) *
 		StrictInclude:               terragruntOptions.StrictInclude,
 		RunTerragrunt:               terragruntOptions.RunTerragrunt,
 		AwsProviderPatchOverrides:   terragruntOptions.AwsProviderPatchOverrides,
+		ReadTerragruntConfigChain:   util.CloneStringList(terragruntOptions.ReadTerragruntConfigChain),
+		WarnOnEmptyInclude:          terragruntOptions.WarnOnEmptyInclude,
+		ExtraFunctions:              terragruntOptions.ExtraFunctions,
+		SkipDependencyOutputCache:   terragruntOptions.SkipDependencyOutputCache,
+		AllowExtraFunctionOverrides: terragruntOptions.AllowExtraFunctionOverrides,
 	}
 }
 
@@ -0,0 +1,12 @@
+dependency "vpc" {
+  config_path = "../vpc"
+}
+
+dependency "network" {
+  config_path = "../network"
+}
+
+inputs = {
+  vpc_id         = dependency.vpc.outputs.vpc_id
+  network_vpc_id = dependency.network.outputs.vpc_id
+}
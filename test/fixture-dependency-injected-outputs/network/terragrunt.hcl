@@ -0,0 +1,13 @@
+# This dependency has the same name as the one in app, but it is not the dependency that the test supplies outputs for
+dependency "vpc" {
+  config_path  = "../vpc"
+  skip_outputs = true
+
+  mock_outputs = {
+    vpc_id = "vpc-network"
+  }
+}
+
+inputs = {
+  vpc_id = dependency.vpc.outputs.vpc_id
+}
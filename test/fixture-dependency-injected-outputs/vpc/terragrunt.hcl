@@ -0,0 +1 @@
+# The outputs of this module are supplied by the test, so it is never applied
@@ -0,0 +1,4 @@
+locals {
+  region = "us-east-1"
+  port   = tonumber("not-a-number")
+}
@@ -0,0 +1,7 @@
+include {
+  path = find_in_parent_folders()
+}
+
+locals {
+  relative_path = path_relative_to_include()
+}
@@ -0,0 +1,4 @@
+# This parent config intentionally sets nothing that would be merged into the child
+locals {
+  unused = "only visible in this config"
+}
@@ -0,0 +1 @@
+# Found by find_in_parent_folders("common.hcl") from below the stop file
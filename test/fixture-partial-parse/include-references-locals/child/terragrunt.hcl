@@ -0,0 +1,7 @@
+locals {
+  parent = find_in_parent_folders()
+}
+
+include {
+  path = local.parent
+}
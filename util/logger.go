@@ -5,6 +5,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/fatih/color"
@@ -46,12 +47,42 @@ func ColorLogf(logger *log.Logger, colorCode *color.Color, fmtString string, fmt
 	logger.Println(logOut)
 }
 
-// GetDiagnosticsWriter returns a hcl2 parsing diagnostics emitter for the current terminal.
+// GetDiagnosticsWriter returns a hcl2 parsing diagnostics emitter for the current terminal. Diagnostics written as a
+// group are sorted by filename and source position first, so that the output does not depend on evaluation order.
 func GetDiagnosticsWriter(parser *hclparse.Parser) hcl.DiagnosticWriter {
 	termColor := terminal.IsTerminal(int(os.Stderr.Fd()))
 	termWidth, _, err := terminal.GetSize(int(os.Stdout.Fd()))
 	if err != nil {
 		termWidth = 80
 	}
-	return hcl.NewDiagnosticTextWriter(os.Stderr, parser.Files(), uint(termWidth), termColor)
+	return sortedDiagnosticsWriter{hcl.NewDiagnosticTextWriter(os.Stderr, parser.Files(), uint(termWidth), termColor)}
+}
+
+// sortedDiagnosticsWriter wraps a hcl.DiagnosticWriter so that groups of diagnostics are emitted in a stable order.
+type sortedDiagnosticsWriter struct {
+	hcl.DiagnosticWriter
+}
+
+func (writer sortedDiagnosticsWriter) WriteDiagnostics(diags hcl.Diagnostics) error {
+	return writer.DiagnosticWriter.WriteDiagnostics(SortDiagnostics(diags))
+}
+
+// SortDiagnostics returns a copy of the given diagnostics ordered by filename and then by source position.
+// Diagnostics that have no source range are kept in their original relative order, after all the others.
+func SortDiagnostics(diags hcl.Diagnostics) hcl.Diagnostics {
+	sorted := make(hcl.Diagnostics, len(diags))
+	copy(sorted, diags)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		left := sorted[i].Subject
+		right := sorted[j].Subject
+		switch {
+		case left == nil || right == nil:
+			return left != nil && right == nil
+		case left.Filename != right.Filename:
+			return left.Filename < right.Filename
+		default:
+			return left.Start.Byte < right.Start.Byte
+		}
+	})
+	return sorted
 }
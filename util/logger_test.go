@@ -0,0 +1,57 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	diagAt := func(filename string, line int, byteOffset int) *hcl.Diagnostic {
+		return &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "test",
+			Subject: &hcl.Range{
+				Filename: filename,
+				Start:    hcl.Pos{Line: line, Column: 1, Byte: byteOffset},
+				End:      hcl.Pos{Line: line, Column: 2, Byte: byteOffset + 1},
+			},
+		}
+	}
+	noSubject := &hcl.Diagnostic{Severity: hcl.DiagError, Summary: "no subject"}
+
+	// Child diagnostics reported before the parent's, and out of order within each file, as would happen when the child
+	// is evaluated before its parent.
+	diags := hcl.Diagnostics{
+		diagAt("child/terragrunt.hcl", 7, 120),
+		noSubject,
+		diagAt("child/terragrunt.hcl", 2, 15),
+		diagAt("terragrunt.hcl", 4, 40),
+		diagAt("terragrunt.hcl", 1, 0),
+	}
+
+	expected := []struct {
+		filename string
+		line     int
+	}{
+		{"child/terragrunt.hcl", 2},
+		{"child/terragrunt.hcl", 7},
+		{"terragrunt.hcl", 1},
+		{"terragrunt.hcl", 4},
+	}
+
+	sorted := SortDiagnostics(diags)
+	assert.Equal(t, sorted, SortDiagnostics(diags))
+	assert.Len(t, sorted, len(diags))
+	for i, exp := range expected {
+		assert.Equal(t, exp.filename, sorted[i].Subject.Filename)
+		assert.Equal(t, exp.line, sorted[i].Subject.Start.Line)
+	}
+	assert.Equal(t, noSubject, sorted[len(sorted)-1])
+
+	// The input is left untouched
+	assert.Equal(t, 7, diags[0].Subject.Start.Line)
+}